@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+)
+
+// Reconcile the configuration at the specified path to exactly match `desired`.
+//
+// The current configuration is fetched with Show and compared against
+// `desired`, which may be any value accepted by Set. Stale values are deleted
+// and missing values are set, all in a single `configure` request. The
+// computed plan is returned, and no request is made if it is empty.
+func (svc *ConfigService) Apply(ctx context.Context, path string, desired any) (Plan, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return Plan{}, err
+	}
+	return svc.ApplyPath(ctx, p, desired)
+}
+
+// Reconcile the configuration at the specified path. See Apply.
+func (svc *ConfigService) ApplyPath(ctx context.Context, path Path, desired any) (Plan, error) {
+	plan, err := svc.PlanPath(ctx, path, desired)
+	if err != nil || plan.IsEmpty() {
+		return plan, err
+	}
+
+	return plan, svc.configure(ctx, plan.Operations())
+}
+
+// Compute the plan Apply would carry out, without changing the configuration.
+func (svc *ConfigService) Plan(ctx context.Context, path string, desired any) (Plan, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return Plan{}, err
+	}
+	return svc.PlanPath(ctx, p, desired)
+}
+
+// Compute the plan ApplyPath would carry out. See Plan.
+func (svc *ConfigService) PlanPath(ctx context.Context, path Path, desired any) (Plan, error) {
+	current, err := svc.ShowPath(ctx, path)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan, err := Diff(current, desired)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan.Path = NewPath(path...)
+	return plan, nil
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Config_Apply(t *testing.T) {
+	var sent []Operation
+	client, ctx := make_stub_client(t, stub_api(t, func(endpoint string, data any) (int, string) {
+		switch endpoint {
+		case "retrieve":
+			return http.StatusOK, `{"success": true, "data": {"host-name": "vyos"}, "error": null}`
+		case "configure":
+			raw, _ := json.Marshal(data)
+			json.Unmarshal(raw, &sent)
+			return http.StatusOK, `{"success": true, "data": null, "error": null}`
+		}
+		return http.StatusNotFound, `{"detail": "Not Found"}`
+	}))
+
+	plan, err := client.Config.Apply(ctx, "system host-name", "router")
+	assert.NoError(t, err)
+	assert.Equal(t, []Operation{
+		{"set", Path{"system", "host-name"}, "router"},
+	}, plan.Operations())
+	assert.Equal(t, plan.Operations(), sent)
+
+	// Nothing is sent when the configuration already matches
+	sent = nil
+	plan, err = client.Config.Apply(ctx, "system host-name", "vyos")
+	assert.NoError(t, err)
+	assert.True(t, plan.IsEmpty())
+	assert.Nil(t, sent)
+}
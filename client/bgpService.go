@@ -0,0 +1,535 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reports the state of BGP sessions
+//
+// Both methods ask for FRR's `json` output first, and fall back to parsing
+// the text output on releases which reject it.
+type BGPService struct{ client *Client }
+
+// The BGP summary of one address family
+type BGPSummary struct {
+	// Address family and subsequent address family, e.g. "ipv4" and "unicast"
+	AFI      string
+	SAFI     string
+	VRF      string
+	RouterID string
+	AS       uint32
+	Peers    []BGPPeerSummary
+}
+
+// A neighbor in a BGPSummary
+type BGPPeerSummary struct {
+	// An address, or an interface name for unnumbered peers
+	Neighbor string
+	Hostname string
+	RemoteAS uint32
+	Version  int
+	MsgRcvd  int
+	MsgSent  int
+	// How long the session has been up, or down if not established
+	Uptime time.Duration
+	// The session state, e.g. "Established", "Active" or "Idle (Admin)"
+	State            string
+	PrefixesReceived int
+	PrefixesSent     int
+	Description      string
+}
+
+// Check whether the session is established
+func (p BGPPeerSummary) Established() bool {
+	return p.State == "Established"
+}
+
+// The detailed state of a BGP neighbor
+type BGPNeighbor struct {
+	Address        string
+	Hostname       string
+	Description    string
+	RemoteAS       uint32
+	LocalAS        uint32
+	RemoteRouterID string
+	LocalRouterID  string
+	State          string
+	// How long the session has been up, zero if not established
+	Uptime                 time.Duration
+	ConnectionsEstablished int
+	ConnectionsDropped     int
+	LastResetReason        string
+	AddressFamilies        []BGPNeighborAddressFamily
+}
+
+// Prefix counts of a BGPNeighbor in one address family
+type BGPNeighborAddressFamily struct {
+	AFI              string
+	SAFI             string
+	PrefixesReceived int
+	PrefixesSent     int
+}
+
+// Check whether the session is established
+func (n BGPNeighbor) Established() bool {
+	return n.State == "Established"
+}
+
+// Returned when a neighbor is not configured
+var ErrBGPNeighborNotFound = errors.New("no such bgp neighbor")
+
+// Return the summary of each address family in `vrf`, or the default VRF if
+// empty. The result is empty if BGP is not running.
+func (svc *BGPService) Summary(ctx context.Context, vrf string) ([]BGPSummary, error) {
+	path := []string{"bgp"}
+	if vrf != "" {
+		path = append(path, "vrf", vrf)
+	}
+	path = append(path, "summary")
+
+	output, isJSON, err := svc.run(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if isJSON {
+		return parseBGPSummaryJSON(output)
+	}
+	return parseBGPSummary(output)
+}
+
+// Return the state of the neighbor `addr`, which is an address or, for
+// unnumbered peers, an interface name
+func (svc *BGPService) Neighbor(ctx context.Context, addr string) (*BGPNeighbor, error) {
+	output, isJSON, err := svc.run(ctx, []string{"bgp", "neighbors", addr})
+	if err != nil {
+		return nil, err
+	}
+	if isJSON {
+		return parseBGPNeighborJSON(output)
+	}
+	return parseBGPNeighbor(output)
+}
+
+// Run `show <path> json`, or `show <path>` if VyOS rejects the former,
+// reporting which output was returned
+func (svc *BGPService) run(ctx context.Context, path []string) (string, bool, error) {
+	output, err := svc.client.Show.Run(ctx, append(append([]string{}, path...), "json"))
+	if err == nil && strings.HasPrefix(strings.TrimSpace(output), "{") {
+		return output, true, nil
+	}
+	if err != nil && !isInvalidCommand(err) {
+		return "", false, err
+	}
+
+	output, err = svc.client.Show.Run(ctx, path)
+	return output, false, err
+}
+
+// Check whether VyOS rejected a command it doesn't know, such as `json` on
+// versions without it
+func isInvalidCommand(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && strings.Contains(strings.ToLower(apiErr.Message), "invalid command")
+}
+
+// Address family keys in FRR's json output
+var bgpAddressFamilies = map[string][2]string{
+	"ipv4Unicast":        {"ipv4", "unicast"},
+	"ipv4Multicast":      {"ipv4", "multicast"},
+	"ipv4LabeledUnicast": {"ipv4", "labeled-unicast"},
+	"ipv4Vpn":            {"ipv4", "vpn"},
+	"ipv4Flowspec":       {"ipv4", "flowspec"},
+	"ipv6Unicast":        {"ipv6", "unicast"},
+	"ipv6Multicast":      {"ipv6", "multicast"},
+	"ipv6LabeledUnicast": {"ipv6", "labeled-unicast"},
+	"ipv6Vpn":            {"ipv6", "vpn"},
+	"ipv6Flowspec":       {"ipv6", "flowspec"},
+	"l2VpnEvpn":          {"l2vpn", "evpn"},
+}
+
+type bgpSummaryJSON struct {
+	RouterID string `json:"routerId"`
+	AS       uint32 `json:"as"`
+	VRFName  string `json:"vrfName"`
+	Peers    map[string]struct {
+		Hostname       string `json:"hostname"`
+		RemoteAS       uint32 `json:"remoteAs"`
+		Version        int    `json:"version"`
+		MsgRcvd        int    `json:"msgRcvd"`
+		MsgSent        int    `json:"msgSent"`
+		PeerUptimeMsec int64  `json:"peerUptimeMsec"`
+		State          string `json:"state"`
+		PfxRcd         int    `json:"pfxRcd"`
+		PfxSnt         int    `json:"pfxSnt"`
+		Desc           string `json:"desc"`
+	} `json:"peers"`
+}
+
+func parseBGPSummaryJSON(data string) ([]BGPSummary, error) {
+	var families map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &families); err != nil {
+		return nil, unexpectedResponse("invalid bgp summary in response from vyos api: %s", err.Error())
+	}
+
+	summaries := []BGPSummary{}
+	for _, key := range sortedKeys(families) {
+		family, ok := bgpAddressFamilies[key]
+		if !ok {
+			continue
+		}
+
+		var s bgpSummaryJSON
+		if err := json.Unmarshal(families[key], &s); err != nil {
+			return nil, unexpectedResponse("invalid bgp summary in response from vyos api: %s", err.Error())
+		}
+
+		summary := BGPSummary{
+			AFI:      family[0],
+			SAFI:     family[1],
+			VRF:      s.VRFName,
+			RouterID: s.RouterID,
+			AS:       s.AS,
+			Peers:    []BGPPeerSummary{},
+		}
+		for _, neighbor := range sortedKeys(s.Peers) {
+			peer := s.Peers[neighbor]
+			summary.Peers = append(summary.Peers, BGPPeerSummary{
+				Neighbor:         neighbor,
+				Hostname:         peer.Hostname,
+				RemoteAS:         peer.RemoteAS,
+				Version:          peer.Version,
+				MsgRcvd:          peer.MsgRcvd,
+				MsgSent:          peer.MsgSent,
+				Uptime:           time.Duration(peer.PeerUptimeMsec) * time.Millisecond,
+				State:            peer.State,
+				PrefixesReceived: peer.PfxRcd,
+				PrefixesSent:     peer.PfxSnt,
+				Description:      peer.Desc,
+			})
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+var bgpSummarySectionPattern = regexp.MustCompile(`^(?P<afi>\S+) (?P<safi>.+?) Summary(?: \(VRF (?P<vrf>\S+)\))?:$`)
+var bgpSummaryRouterPattern = regexp.MustCompile(`^BGP router identifier (?P<id>\S+), local AS number (?P<as>\d+)(?: vrf-id \S+)?`)
+var bgpSummaryHeaderPattern = regexp.MustCompile(`^Neighbor\s+V\s+AS\s+MsgRcvd\s+MsgSent\s+.*State/PfxRcd`)
+
+func parseBGPSummary(data string) ([]BGPSummary, error) {
+	summaries := []BGPSummary{}
+
+	var summary *BGPSummary
+	inTable := false
+	hasPfxSnt := false
+	pending := ""
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			inTable = false
+			continue
+		}
+
+		if match, ok := matchStringNamed(bgpSummarySectionPattern, line); ok {
+			summaries = append(summaries, BGPSummary{
+				AFI:   strings.ToLower(match["afi"]),
+				SAFI:  strings.ReplaceAll(strings.ToLower(match["safi"]), " ", "-"),
+				VRF:   match["vrf"],
+				Peers: []BGPPeerSummary{},
+			})
+			summary = &summaries[len(summaries)-1]
+			continue
+		}
+		if summary == nil {
+			// FRR 7 omits the section header when showing a single family
+			if !bgpSummaryRouterPattern.MatchString(line) {
+				continue
+			}
+			summaries = append(summaries, BGPSummary{AFI: "ipv4", SAFI: "unicast", Peers: []BGPPeerSummary{}})
+			summary = &summaries[len(summaries)-1]
+		}
+
+		if match, ok := matchStringNamed(bgpSummaryRouterPattern, line); ok {
+			summary.RouterID = match["id"]
+			as, _ := strconv.ParseUint(match["as"], 10, 32)
+			summary.AS = uint32(as)
+			continue
+		}
+		if bgpSummaryHeaderPattern.MatchString(line) {
+			inTable = true
+			hasPfxSnt = strings.Contains(line, "PfxSnt")
+			continue
+		}
+		if !inTable || strings.HasPrefix(line, "Total number of neighbors") {
+			continue
+		}
+
+		// Long neighbor names are printed on a line of their own
+		fields := strings.Fields(line)
+		if len(fields) == 1 {
+			pending = fields[0]
+			continue
+		}
+		if pending != "" {
+			fields = append([]string{pending}, fields...)
+			pending = ""
+		}
+
+		peer, err := parseBGPPeerRow(fields, hasPfxSnt)
+		if err != nil {
+			return nil, unexpectedResponse("invalid bgp neighbor in response from vyos api:\n%s", line)
+		}
+		summary.Peers = append(summary.Peers, peer)
+	}
+
+	return summaries, nil
+}
+
+// Parse the fields of a row of the summary table
+func parseBGPPeerRow(fields []string, hasPfxSnt bool) (BGPPeerSummary, error) {
+	if len(fields) < 10 {
+		return BGPPeerSummary{}, fmt.Errorf("expected at least 10 fields")
+	}
+
+	peer := BGPPeerSummary{Neighbor: fields[0]}
+	var err error
+	if peer.Version, err = strconv.Atoi(fields[1]); err != nil {
+		return peer, err
+	}
+	if peer.MsgRcvd, err = strconv.Atoi(fields[3]); err != nil {
+		return peer, err
+	}
+	if peer.MsgSent, err = strconv.Atoi(fields[4]); err != nil {
+		return peer, err
+	}
+	as, err := strconv.ParseUint(fields[2], 10, 32)
+	if err != nil {
+		return peer, err
+	}
+	peer.RemoteAS = uint32(as)
+
+	peer.Uptime, err = parseFRRDuration(fields[8])
+	if err != nil {
+		return peer, err
+	}
+
+	// The state column holds the received prefix count once established
+	rest := fields[9:]
+	if n, err := strconv.Atoi(rest[0]); err == nil {
+		peer.State = "Established"
+		peer.PrefixesReceived = n
+		rest = rest[1:]
+	} else {
+		peer.State = rest[0]
+		rest = rest[1:]
+		// As in `Idle (Admin)`
+		if len(rest) > 0 && strings.HasPrefix(rest[0], "(") {
+			peer.State += " " + rest[0]
+			rest = rest[1:]
+		}
+	}
+
+	if hasPfxSnt && len(rest) > 0 {
+		if n, err := strconv.Atoi(rest[0]); err == nil {
+			peer.PrefixesSent = n
+			rest = rest[1:]
+		}
+	}
+	if len(rest) > 0 && strings.Join(rest, " ") != "N/A" {
+		peer.Description = strings.Join(rest, " ")
+	}
+
+	return peer, nil
+}
+
+var frrDurationPattern = regexp.MustCompile(`^(?:(?P<h>\d{2}):(?P<m>\d{2}):(?P<s>\d{2})|(?P<units>(?:\d+[wdhm])+))$`)
+
+// Parse a duration as shown by FRR, e.g. 01:02:03, 1d02h03m or 01w2d03h.
+// `never` is zero.
+func parseFRRDuration(s string) (time.Duration, error) {
+	if s == "never" {
+		return 0, nil
+	}
+
+	match, ok := matchStringNamed(frrDurationPattern, s)
+	if !ok {
+		return 0, fmt.Errorf("invalid duration %s", s)
+	}
+
+	if match["units"] == "" {
+		h, _ := strconv.Atoi(match["h"])
+		m, _ := strconv.Atoi(match["m"])
+		sec, _ := strconv.Atoi(match["s"])
+		return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second, nil
+	}
+
+	d := time.Duration(0)
+	for _, unit := range uptimeUnitPattern.FindAllStringSubmatch(match["units"], -1) {
+		n, _ := strconv.Atoi(unit[1])
+		d += time.Duration(n) * uptimeUnits[unit[2]]
+	}
+	return d, nil
+}
+
+type bgpNeighborJSON struct {
+	RemoteAS               uint32 `json:"remoteAs"`
+	LocalAS                uint32 `json:"localAs"`
+	Hostname               string `json:"hostname"`
+	NbrDesc                string `json:"nbrDesc"`
+	RemoteRouterID         string `json:"remoteRouterId"`
+	LocalRouterID          string `json:"localRouterId"`
+	BGPState               string `json:"bgpState"`
+	BGPTimerUpMsec         int64  `json:"bgpTimerUpMsec"`
+	ConnectionsEstablished int    `json:"connectionsEstablished"`
+	ConnectionsDropped     int    `json:"connectionsDropped"`
+	LastResetDueTo         string `json:"lastResetDueTo"`
+	AddressFamilyInfo      map[string]struct {
+		AcceptedPrefixCounter int `json:"acceptedPrefixCounter"`
+		SentPrefixCounter     int `json:"sentPrefixCounter"`
+	} `json:"addressFamilyInfo"`
+}
+
+func parseBGPNeighborJSON(data string) (*BGPNeighbor, error) {
+	var neighbors map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &neighbors); err != nil {
+		return nil, unexpectedResponse("invalid bgp neighbor in response from vyos api: %s", err.Error())
+	}
+
+	// Unknown neighbors are reported with a warning instead
+	delete(neighbors, "warning")
+	if len(neighbors) == 0 {
+		return nil, ErrBGPNeighborNotFound
+	}
+	if len(neighbors) > 1 {
+		return nil, unexpectedResponse("expected a single bgp neighbor in response from vyos api, got %d", len(neighbors))
+	}
+
+	address := sortedKeys(neighbors)[0]
+	var n bgpNeighborJSON
+	if err := json.Unmarshal(neighbors[address], &n); err != nil {
+		return nil, unexpectedResponse("invalid bgp neighbor in response from vyos api: %s", err.Error())
+	}
+
+	neighbor := &BGPNeighbor{
+		Address:                address,
+		Hostname:               n.Hostname,
+		Description:            n.NbrDesc,
+		RemoteAS:               n.RemoteAS,
+		LocalAS:                n.LocalAS,
+		RemoteRouterID:         n.RemoteRouterID,
+		LocalRouterID:          n.LocalRouterID,
+		State:                  n.BGPState,
+		ConnectionsEstablished: n.ConnectionsEstablished,
+		ConnectionsDropped:     n.ConnectionsDropped,
+		LastResetReason:        n.LastResetDueTo,
+		AddressFamilies:        []BGPNeighborAddressFamily{},
+	}
+	if neighbor.Established() {
+		neighbor.Uptime = time.Duration(n.BGPTimerUpMsec) * time.Millisecond
+	}
+
+	for _, key := range sortedKeys(n.AddressFamilyInfo) {
+		family, ok := bgpAddressFamilies[key]
+		if !ok {
+			continue
+		}
+		info := n.AddressFamilyInfo[key]
+		neighbor.AddressFamilies = append(neighbor.AddressFamilies, BGPNeighborAddressFamily{
+			AFI:              family[0],
+			SAFI:             family[1],
+			PrefixesReceived: info.AcceptedPrefixCounter,
+			PrefixesSent:     info.SentPrefixCounter,
+		})
+	}
+
+	return neighbor, nil
+}
+
+var bgpNeighborPattern = regexp.MustCompile(`^BGP neighbor is (?P<address>[^,]+), remote AS (?P<remote>\d+), local AS (?P<local>\d+)`)
+var bgpNeighborHostnamePattern = regexp.MustCompile(`^Hostname: (?P<hostname>\S+)`)
+var bgpNeighborDescriptionPattern = regexp.MustCompile(`^Description: (?P<description>.*)$`)
+var bgpNeighborRouterIDPattern = regexp.MustCompile(`remote router ID (?P<remote>[^,\s]+), local router ID (?P<local>[^,\s]+)`)
+var bgpNeighborStatePattern = regexp.MustCompile(`^BGP state = (?P<state>[^,]+)(?:, up for (?P<uptime>\S+))?`)
+var bgpNeighborConnectionsPattern = regexp.MustCompile(`^Connections established (?P<established>\d+); dropped (?P<dropped>\d+)`)
+var bgpNeighborResetPattern = regexp.MustCompile(`^Last reset \S+,\s+(?P<reason>.+)$`)
+var bgpNeighborFamilyPattern = regexp.MustCompile(`^For address family: (?P<afi>\S+) (?P<safi>.+)$`)
+var bgpNeighborAcceptedPattern = regexp.MustCompile(`^(?P<count>\d+) accepted prefixes`)
+var bgpNeighborSentPattern = regexp.MustCompile(`^Prefixes Current:\s+(?P<sent>\d+)\s+(?P<received>\d+)`)
+
+func parseBGPNeighbor(data string) (*BGPNeighbor, error) {
+	var neighbor *BGPNeighbor
+	var family *BGPNeighborAddressFamily
+
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+
+		if match, ok := matchStringNamed(bgpNeighborPattern, line); ok {
+			if neighbor != nil {
+				return nil, unexpectedResponse("expected a single bgp neighbor in response from vyos api")
+			}
+			remote, _ := strconv.ParseUint(match["remote"], 10, 32)
+			local, _ := strconv.ParseUint(match["local"], 10, 32)
+			neighbor = &BGPNeighbor{
+				Address:         match["address"],
+				RemoteAS:        uint32(remote),
+				LocalAS:         uint32(local),
+				AddressFamilies: []BGPNeighborAddressFamily{},
+			}
+			continue
+		}
+		if neighbor == nil {
+			continue
+		}
+
+		if match, ok := matchStringNamed(bgpNeighborHostnamePattern, line); ok {
+			neighbor.Hostname = match["hostname"]
+		} else if match, ok := matchStringNamed(bgpNeighborDescriptionPattern, line); ok {
+			neighbor.Description = match["description"]
+		} else if match, ok := matchStringNamed(bgpNeighborStatePattern, line); ok {
+			neighbor.State = match["state"]
+			if match["uptime"] != "" {
+				uptime, err := parseFRRDuration(match["uptime"])
+				if err != nil {
+					return nil, unexpectedResponse("invalid bgp neighbor in response from vyos api:\n%s", line)
+				}
+				neighbor.Uptime = uptime
+			}
+		} else if match, ok := matchStringNamed(bgpNeighborConnectionsPattern, line); ok {
+			neighbor.ConnectionsEstablished, _ = strconv.Atoi(match["established"])
+			neighbor.ConnectionsDropped, _ = strconv.Atoi(match["dropped"])
+		} else if match, ok := matchStringNamed(bgpNeighborResetPattern, line); ok {
+			neighbor.LastResetReason = match["reason"]
+		} else if match, ok := matchStringNamed(bgpNeighborFamilyPattern, line); ok {
+			neighbor.AddressFamilies = append(neighbor.AddressFamilies, BGPNeighborAddressFamily{
+				AFI:  strings.ToLower(match["afi"]),
+				SAFI: strings.ReplaceAll(strings.ToLower(match["safi"]), " ", "-"),
+			})
+			family = &neighbor.AddressFamilies[len(neighbor.AddressFamilies)-1]
+		} else if match, ok := matchStringNamed(bgpNeighborAcceptedPattern, line); ok && family != nil {
+			family.PrefixesReceived, _ = strconv.Atoi(match["count"])
+		} else if match, ok := matchStringNamed(bgpNeighborSentPattern, line); ok && family != nil {
+			family.PrefixesSent, _ = strconv.Atoi(match["sent"])
+		}
+
+		if match, ok := matchStringNamed(bgpNeighborRouterIDPattern, line); ok {
+			neighbor.RemoteRouterID = match["remote"]
+			neighbor.LocalRouterID = match["local"]
+		}
+	}
+
+	if neighbor == nil {
+		if strings.Contains(data, "No such neighbor") {
+			return nil, ErrBGPNeighborNotFound
+		}
+		return nil, unexpectedResponse("could not find bgp neighbor in response from vyos api:\n%s", data)
+	}
+	return neighbor, nil
+}
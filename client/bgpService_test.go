@@ -0,0 +1,395 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// FRR 8, as in VyOS 1.4
+const bgpSummaryJSONOutput = `{
+"ipv4Unicast":{
+  "routerId":"192.0.2.1",
+  "as":65001,
+  "vrfId":0,
+  "vrfName":"default",
+  "tableVersion":5,
+  "peerCount":2,
+  "peers":{
+    "192.0.2.3":{
+      "remoteAs":65003,
+      "localAs":65001,
+      "version":4,
+      "msgRcvd":0,
+      "msgSent":0,
+      "peerUptime":"never",
+      "peerUptimeMsec":0,
+      "pfxRcd":0,
+      "pfxSnt":0,
+      "state":"Active",
+      "peerState":"OK",
+      "idType":"ipv4"
+    },
+    "192.0.2.2":{
+      "hostname":"peer1",
+      "remoteAs":65002,
+      "localAs":65001,
+      "version":4,
+      "msgRcvd":100,
+      "msgSent":101,
+      "peerUptime":"01:02:03",
+      "peerUptimeMsec":3723000,
+      "pfxRcd":2,
+      "pfxSnt":3,
+      "state":"Established",
+      "peerState":"OK",
+      "desc":"upstream",
+      "idType":"ipv4"
+    }
+  },
+  "failedPeers":1,
+  "totalPeers":2
+},
+"ipv6Unicast":{
+  "routerId":"192.0.2.1",
+  "as":65001,
+  "vrfName":"default",
+  "peers":{
+    "2001:db8::2":{
+      "remoteAs":65002,
+      "version":4,
+      "msgRcvd":50,
+      "msgSent":51,
+      "peerUptimeMsec":93784000,
+      "pfxRcd":10,
+      "pfxSnt":1,
+      "state":"Established"
+    }
+  }
+}
+}`
+
+// FRR 8, as in VyOS 1.4
+const bgpSummaryOutput = `
+IPv4 Unicast Summary (VRF default):
+BGP router identifier 192.0.2.1, local AS number 65001 vrf-id 0
+BGP table version 5
+RIB entries 3, using 552 bytes of memory
+Peers 3, using 2172 KiB of memory
+
+Neighbor        V         AS   MsgRcvd   MsgSent   TblVer  InQ OutQ  Up/Down State/PfxRcd   PfxSnt Desc
+192.0.2.2       4      65002       100       101        0    0    0 01:02:03            2        3 upstream link
+192.0.2.3       4      65003         0         0        0    0    0    never       Active        0 N/A
+192.0.2.4       4      65004         0         0        0    0    0 1d02h03m Idle (Admin)        0 N/A
+
+Total number of neighbors 3
+
+IPv6 Unicast Summary (VRF default):
+BGP router identifier 192.0.2.1, local AS number 65001 vrf-id 0
+BGP table version 2
+
+Neighbor        V         AS   MsgRcvd   MsgSent   TblVer  InQ OutQ  Up/Down State/PfxRcd   PfxSnt Desc
+2001:db8:ffff::2
+                4      65002        50        51        0    0    0 1d02h03m           10        1 N/A
+
+Total number of neighbors 1
+`
+
+// FRR 7, as in VyOS 1.3
+const bgpSummaryOutputFRR7 = `
+BGP router identifier 192.0.2.1, local AS number 65001 vrf-id 0
+BGP table version 5
+RIB entries 3, using 576 bytes of memory
+Peers 1, using 21 KiB of memory
+
+Neighbor        V         AS MsgRcvd MsgSent   TblVer  InQ OutQ  Up/Down State/PfxRcd
+192.0.2.2       4      65002     100     101        0    0    0 01w2d03h            7
+
+Total number of neighbors 1
+`
+
+const bgpNeighborJSONOutput = `{
+"192.0.2.2":{
+  "remoteAs":65002,
+  "localAs":65001,
+  "nbrExternalLink":true,
+  "hostname":"peer1",
+  "nbrDesc":"upstream",
+  "bgpVersion":4,
+  "remoteRouterId":"192.0.2.2",
+  "localRouterId":"192.0.2.1",
+  "bgpState":"Established",
+  "bgpTimerUpMsec":3723000,
+  "bgpTimerUpString":"01:02:03",
+  "connectionsEstablished":1,
+  "connectionsDropped":0,
+  "lastResetDueTo":"Waiting for peer OPEN",
+  "addressFamilyInfo":{
+    "ipv4Unicast":{
+      "acceptedPrefixCounter":2,
+      "sentPrefixCounter":3
+    }
+  }
+}
+}`
+
+const bgpNeighborOutput = `BGP neighbor is 192.0.2.2, remote AS 65002, local AS 65001, external link
+  Hostname: peer1
+ Description: upstream
+  BGP version 4, remote router ID 192.0.2.2, local router ID 192.0.2.1
+  BGP state = Established, up for 01:02:03
+  Last read 00:00:02, Last write 00:00:02
+  Message statistics:
+                         Sent       Rcvd
+    Opens:                  1          1
+ For address family: IPv4 Unicast
+  Update group 1, subgroup 1
+  Community attribute sent to this neighbor(all)
+  2 accepted prefixes
+
+ For address family: IPv6 Unicast
+  Not part of any update group
+  0 accepted prefixes
+
+  Connections established 1; dropped 0
+  Last reset 00:10:00,  Waiting for peer OPEN
+Local host: 192.0.2.1, Local port: 179
+`
+
+func TestUnit_BGP_ParseSummaryJSON(t *testing.T) {
+	summaries, err := parseBGPSummaryJSON(bgpSummaryJSONOutput)
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 2)
+
+	assert.Equal(t, BGPSummary{
+		AFI:      "ipv4",
+		SAFI:     "unicast",
+		VRF:      "default",
+		RouterID: "192.0.2.1",
+		AS:       65001,
+		Peers: []BGPPeerSummary{
+			{
+				Neighbor:         "192.0.2.2",
+				Hostname:         "peer1",
+				RemoteAS:         65002,
+				Version:          4,
+				MsgRcvd:          100,
+				MsgSent:          101,
+				Uptime:           time.Hour + 2*time.Minute + 3*time.Second,
+				State:            "Established",
+				PrefixesReceived: 2,
+				PrefixesSent:     3,
+				Description:      "upstream",
+			},
+			{Neighbor: "192.0.2.3", RemoteAS: 65003, Version: 4, State: "Active"},
+		},
+	}, summaries[0])
+	assert.True(t, summaries[0].Peers[0].Established())
+	assert.False(t, summaries[0].Peers[1].Established())
+
+	assert.Equal(t, "ipv6", summaries[1].AFI)
+	assert.Equal(t, 10, summaries[1].Peers[0].PrefixesReceived)
+	assert.Equal(t, 26*time.Hour+3*time.Minute+4*time.Second, summaries[1].Peers[0].Uptime)
+
+	summaries, err = parseBGPSummaryJSON("{}")
+	assert.NoError(t, err)
+	assert.Empty(t, summaries)
+
+	_, err = parseBGPSummaryJSON(`{"ipv4Unicast": []}`)
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+}
+
+func TestUnit_BGP_ParseSummary(t *testing.T) {
+	summaries, err := parseBGPSummary(bgpSummaryOutput)
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 2)
+
+	assert.Equal(t, "ipv4", summaries[0].AFI)
+	assert.Equal(t, "unicast", summaries[0].SAFI)
+	assert.Equal(t, "default", summaries[0].VRF)
+	assert.Equal(t, "192.0.2.1", summaries[0].RouterID)
+	assert.Equal(t, uint32(65001), summaries[0].AS)
+	assert.Equal(t, []BGPPeerSummary{
+		{
+			Neighbor:         "192.0.2.2",
+			RemoteAS:         65002,
+			Version:          4,
+			MsgRcvd:          100,
+			MsgSent:          101,
+			Uptime:           time.Hour + 2*time.Minute + 3*time.Second,
+			State:            "Established",
+			PrefixesReceived: 2,
+			PrefixesSent:     3,
+			Description:      "upstream link",
+		},
+		{Neighbor: "192.0.2.3", RemoteAS: 65003, Version: 4, State: "Active"},
+		{Neighbor: "192.0.2.4", RemoteAS: 65004, Version: 4, State: "Idle (Admin)", Uptime: 26*time.Hour + 3*time.Minute},
+	}, summaries[0].Peers)
+
+	// Wrapped neighbor
+	assert.Equal(t, "ipv6", summaries[1].AFI)
+	assert.Equal(t, BGPPeerSummary{
+		Neighbor:         "2001:db8:ffff::2",
+		RemoteAS:         65002,
+		Version:          4,
+		MsgRcvd:          50,
+		MsgSent:          51,
+		Uptime:           26*time.Hour + 3*time.Minute,
+		State:            "Established",
+		PrefixesReceived: 10,
+		PrefixesSent:     1,
+	}, summaries[1].Peers[0])
+}
+
+func TestUnit_BGP_ParseSummaryFRR7(t *testing.T) {
+	summaries, err := parseBGPSummary(bgpSummaryOutputFRR7)
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 1)
+
+	assert.Equal(t, "ipv4", summaries[0].AFI)
+	assert.Equal(t, "unicast", summaries[0].SAFI)
+	assert.Equal(t, []BGPPeerSummary{{
+		Neighbor:         "192.0.2.2",
+		RemoteAS:         65002,
+		Version:          4,
+		MsgRcvd:          100,
+		MsgSent:          101,
+		Uptime:           9*24*time.Hour + 3*time.Hour,
+		State:            "Established",
+		PrefixesReceived: 7,
+	}}, summaries[0].Peers)
+}
+
+func TestUnit_BGP_ParseSummaryInvalid(t *testing.T) {
+	summaries, err := parseBGPSummary("% BGP instance not found\n")
+	assert.NoError(t, err)
+	assert.Empty(t, summaries)
+
+	_, err = parseBGPSummary("IPv4 Unicast Summary:\n" +
+		"Neighbor        V         AS   MsgRcvd   MsgSent   TblVer  InQ OutQ  Up/Down State/PfxRcd\n" +
+		"192.0.2.2       4      65002       foo       101        0    0    0 01:02:03            2\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+}
+
+func TestUnit_BGP_ParseNeighbor(t *testing.T) {
+	expected := &BGPNeighbor{
+		Address:                "192.0.2.2",
+		Hostname:               "peer1",
+		Description:            "upstream",
+		RemoteAS:               65002,
+		LocalAS:                65001,
+		RemoteRouterID:         "192.0.2.2",
+		LocalRouterID:          "192.0.2.1",
+		State:                  "Established",
+		Uptime:                 time.Hour + 2*time.Minute + 3*time.Second,
+		ConnectionsEstablished: 1,
+		LastResetReason:        "Waiting for peer OPEN",
+		AddressFamilies: []BGPNeighborAddressFamily{
+			{AFI: "ipv4", SAFI: "unicast", PrefixesReceived: 2, PrefixesSent: 3},
+		},
+	}
+
+	neighbor, err := parseBGPNeighborJSON(bgpNeighborJSONOutput)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, neighbor)
+	assert.True(t, neighbor.Established())
+
+	// The text output only shows sent prefix counts in detailed statistics
+	expected.AddressFamilies = []BGPNeighborAddressFamily{
+		{AFI: "ipv4", SAFI: "unicast", PrefixesReceived: 2},
+		{AFI: "ipv6", SAFI: "unicast"},
+	}
+	neighbor, err = parseBGPNeighbor(bgpNeighborOutput)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, neighbor)
+}
+
+func TestUnit_BGP_ParseNeighborNotFound(t *testing.T) {
+	_, err := parseBGPNeighborJSON(`{"warning":"No such neighbor in this view/vrf"}`)
+	assert.ErrorIs(t, err, ErrBGPNeighborNotFound)
+
+	_, err = parseBGPNeighbor("% No such neighbor in this view/vrf\n")
+	assert.ErrorIs(t, err, ErrBGPNeighborNotFound)
+
+	_, err = parseBGPNeighbor("Invalid command\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+}
+
+func TestUnit_BGP_ParseFRRDuration(t *testing.T) {
+	for s, expected := range map[string]time.Duration{
+		"never":    0,
+		"00:00:05": 5 * time.Second,
+		"23:59:59": 24*time.Hour - time.Second,
+		"1d02h03m": 26*time.Hour + 3*time.Minute,
+		"01w2d03h": 9*24*time.Hour + 3*time.Hour,
+	} {
+		d, err := parseFRRDuration(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, d, s)
+	}
+
+	_, err := parseFRRDuration("Active")
+	assert.Error(t, err)
+}
+
+func TestUnit_BGP_JSON(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_show(t, map[string]string{
+		"bgp summary json":             bgpSummaryJSONOutput,
+		"bgp vrf red summary json":     "{}",
+		"bgp neighbors 192.0.2.2 json": bgpNeighborJSONOutput,
+		"bgp neighbors 192.0.2.9 json": `{"warning":"No such neighbor in this view/vrf"}`,
+	}))
+
+	summaries, err := client.BGP.Summary(ctx, "")
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 2)
+
+	summaries, err = client.BGP.Summary(ctx, "red")
+	assert.NoError(t, err)
+	assert.Empty(t, summaries)
+
+	neighbor, err := client.BGP.Neighbor(ctx, "192.0.2.2")
+	assert.NoError(t, err)
+	assert.Equal(t, "peer1", neighbor.Hostname)
+
+	_, err = client.BGP.Neighbor(ctx, "192.0.2.9")
+	assert.ErrorIs(t, err, ErrBGPNeighborNotFound)
+
+	resp, err := client.Show.Parse(ctx, []string{"bgp", "summary", "json"})
+	assert.NoError(t, err)
+	assert.Len(t, resp, 2)
+}
+
+func TestUnit_BGP_TextFallback(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_show(t, map[string]string{
+		"bgp summary":             bgpSummaryOutputFRR7,
+		"bgp neighbors 192.0.2.2": bgpNeighborOutput,
+	}))
+
+	summaries, err := client.BGP.Summary(ctx, "")
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, 7, summaries[0].Peers[0].PrefixesReceived)
+
+	neighbor, err := client.BGP.Neighbor(ctx, "192.0.2.2")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(65002), neighbor.RemoteAS)
+
+	_, err = client.BGP.Summary(ctx, "blue")
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+}
+
+func TestUnit_BGP_NoFallbackOnError(t *testing.T) {
+	requests := 0
+	client, ctx := make_stub_client(t, stub_api(t, func(endpoint string, data any) (int, string) {
+		requests++
+		return http.StatusForbidden, `{"success": false, "data": null, "error": "Invalid API key"}`
+	}))
+
+	_, err := client.BGP.Summary(ctx, "")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.Equal(t, 1, requests)
+}
@@ -6,8 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"strings"
-	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -17,35 +15,104 @@ type Client struct {
 	url   string
 	key   string
 	resty *resty.Client
-
-	mutex *sync.Mutex
+	// Used for longRunningEndpoints, with its own timeout
+	longResty *resty.Client
+
+	scheduler *scheduler
+	retry     RetryPolicy
+	logger    Logger
+	// Hooks called around each request attempt
+	beforeRequest []BeforeRequestHook
+	afterResponse []AfterResponseHook
+	// Middleware, and the request chain built from it
+	middleware []Middleware
+	handler    RequestFunc
+	// Error from building the client, returned by every request
+	err error
 
 	Config          *ConfigService
 	ContainerImages *ContainerImageService
+	GraphQL         *GraphQLService
+	Show            *ShowService
+	Interfaces      *InterfaceService
+	Routes          *RouteService
+	BGP             *BGPService
+	DHCP            *DHCPService
+	System          *SystemService
+	Images          *ImageService
 }
 type ConfigService struct{ client *Client }
 
-func New(url string, key string) *Client {
-	return NewWithClient(&http.Client{Timeout: 10 * time.Second}, url, key)
-}
+// Create a client for the VyOS API at `url`, authenticating with `key`.
+//
+// Options which fail to apply, such as an unreadable CA file, cause every
+// request to return the error.
+func New(url string, key string, opts ...Option) *Client {
+	o := &options{timeout: 10 * time.Second, imageTimeout: 30 * time.Minute}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	c, err := o.buildHTTPClient()
+	if err != nil {
+		c = &http.Client{}
+	}
+	long := *c
+	long.Timeout = o.imageTimeout
 
-func NewWithClient(c *http.Client, url string, key string) *Client {
 	client := &Client{
-		url,
-		key,
-		resty.NewWithClient(c),
-		&sync.Mutex{},
+		url:       joinURL(url, o.basePath),
+		key:       key,
+		resty:     resty.NewWithClient(c),
+		longResty: resty.NewWithClient(&long),
+		scheduler: newScheduler(),
+		logger:    o.logger,
+		err:       err,
+
+		beforeRequest: o.beforeRequest,
+		afterResponse: o.afterResponse,
+	}
 
-		nil,
-		nil,
+	for _, r := range []*resty.Client{client.resty, client.longResty} {
+		if o.userAgent != "" {
+			r.SetHeader("User-Agent", o.userAgent)
+		}
+		r.SetHeaders(o.headers)
 	}
+	if o.retry != nil {
+		client.SetRetryPolicy(*o.retry)
+	}
+	if o.writePolicy != nil {
+		client.SetWritePolicy(*o.writePolicy)
+	}
+	client.SetMaxConcurrency(o.maxConcurrency)
+	client.Use(o.middleware...)
 
 	client.Config = &ConfigService{client}
 	client.ContainerImages = &ContainerImageService{client}
+	client.GraphQL = &GraphQLService{client}
+	client.Show = newShowService(client)
+	client.Interfaces = &InterfaceService{client}
+	client.Routes = &RouteService{client}
+	client.BGP = &BGPService{client}
+	client.DHCP = &DHCPService{client}
+	client.System = &SystemService{client: client}
+	client.Images = &ImageService{client}
 
 	return client
 }
 
+// Create a client which makes requests with `c`. See New and WithHTTPClient.
+func NewWithClient(c *http.Client, url string, key string, opts ...Option) *Client {
+	return New(url, key, append(opts, WithHTTPClient(c))...)
+}
+
+// Endpoints whose requests can take much longer than others, and are bounded
+// by the image timeout rather than the client-wide one
+var longRunningEndpoints = map[string]bool{
+	"image": true,
+}
+
 type response struct {
 	Success bool
 	Data    any
@@ -53,67 +120,148 @@ type response struct {
 }
 
 // Post a raw request with `payload` to `endpoint`.
+//
+// Safe for concurrent use. Requests which modify the configuration are
+// ordered according to the client's WritePolicy, and failed requests are
+// retried according to its RetryPolicy.
 func (c *Client) Request(ctx context.Context, endpoint string, payload any) (any, error) {
+	if c.handler != nil {
+		return c.handler(ctx, endpoint, payload)
+	}
+	return c.request(ctx, endpoint, payload)
+}
+
+// Post a request, retrying it as needed, without any middleware
+func (c *Client) request(ctx context.Context, endpoint string, payload any) (any, error) {
+	if c.err != nil {
+		return nil, fmt.Errorf("invalid client options: %w", c.err)
+	}
+
 	data, err := json.Marshal(payload)
 	if err != nil {
-		return nil, errors.New("failed to marshal request payload")
+		return nil, fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	post := c.post
+	if endpoint == graphQLEndpoint {
+		post = c.postGraphQL
+	}
+
+	attempts := 1
+	if c.retry.retryable(endpoint, payload) {
+		attempts = c.retry.MaxAttempts
+	}
+
+	// Hooks see the payload as sent, which may differ from `payload` in type
+	var observedPayload any
+	observed := c.observed()
+	if observed {
+		observedPayload = c.redactPayload(data)
+	}
+
+	for attempt := 1; ; attempt++ {
+		var event *ResponseEvent
+		if observed {
+			event = &ResponseEvent{RequestEvent: RequestEvent{endpoint, observedPayload, attempt}}
+			c.beforeRequestHooks(ctx, &event.RequestEvent)
+		}
+
+		start := time.Now()
+		resp, status, err := post(ctx, endpoint, data)
+		if observed {
+			event.Duration = time.Since(start)
+			event.StatusCode = status
+			event.Data = resp
+			event.Err = err
+			c.afterResponseHooks(ctx, event)
+		}
+
+		if err == nil || attempt >= attempts || !c.retry.retryOn(err) {
+			return resp, err
+		}
+
+		err = c.retry.wait(ctx, attempt)
+		if err != nil {
+			return nil, err
+		}
 	}
+}
 
+// Post a single attempt of a request with the marshaled payload `data`,
+// returning the HTTP status of the response if one was received
+func (c *Client) post(ctx context.Context, endpoint string, data []byte) (any, int, error) {
 	url := c.url + "/" + endpoint
-	c.mutex.Lock()
-	resp, err := c.resty.R().
+	release, err := c.scheduler.acquire(ctx, writeEndpoints[endpoint])
+	if err != nil {
+		return nil, 0, err
+	}
+	rc := c.resty
+	if longRunningEndpoints[endpoint] {
+		rc = c.longResty
+	}
+	resp, err := rc.R().
 		SetContext(ctx).
 		SetFormData(map[string]string{
 			"key":  c.key,
 			"data": string(data),
 		}).
 		Post(url)
-	c.mutex.Unlock()
+	release()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	statusCode := resp.StatusCode()
 	body := resp.Body()
+	r := new(response)
 	if statusCode < 200 || statusCode >= 300 {
-		return nil, fmt.Errorf(
-			"received non-successful (%d) response from vyos api (%s).\n%s",
-			statusCode,
-			url,
-			body,
-		)
+		// Error responses usually still carry a message from VyOS
+		apiErr := &APIError{statusCode, endpoint, url, body, ""}
+		if json.Unmarshal(body, &r) == nil && r.Error != nil {
+			apiErr.Message = *r.Error
+		}
+		return nil, statusCode, apiErr
 	}
 
-	r := new(response)
 	err = json.Unmarshal(body, &r)
 	if err != nil {
-		return nil, err
+		return nil, statusCode, unexpectedResponse("%s", err.Error())
 	}
 
 	// Handle errors from the API
 	if r.Error != nil {
-		return nil, errors.New(*r.Error)
+		return nil, statusCode, &APIError{statusCode, endpoint, url, body, *r.Error}
 	}
 
-	return r.Data, err
+	return r.Data, statusCode, nil
+}
+
+// Check that the API is reachable and accepts our key
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Request(ctx, "retrieve", map[string]any{
+		"op":   "exists",
+		"path": []string{"system"},
+	})
+	return err
 }
 
 // Return the configuration tree at the specified path
 func (svc *ConfigService) Show(ctx context.Context, path string) (any, error) {
-	components := strings.Split(path, " ")
-	terminal := components[len(components)-1]
-
-	path_components := components
-	if path == "" {
-		path_components = []string{}
+	p, err := ParsePath(path)
+	if err != nil {
+		return nil, err
 	}
+	return svc.ShowPath(ctx, p)
+}
 
+// Return the configuration tree at the specified path
+func (svc *ConfigService) ShowPath(ctx context.Context, path Path) (any, error) {
 	resp, err := svc.client.Request(ctx, "retrieve", map[string]any{
 		"op":   "showConfig",
-		"path": path_components,
+		"path": NewPath(path...),
 	})
 	if err != nil {
-		if strings.Contains(err.Error(), "specified path is empty") {
+		if errors.Is(err, ErrPathEmpty) {
 			// If we get an empty path error, consume it and return nil
 			return nil, nil
 		} else {
@@ -123,10 +271,10 @@ func (svc *ConfigService) Show(ctx context.Context, path string) (any, error) {
 
 	obj, ok := resp.(map[string]any)
 	if !ok {
-		return nil, errors.New("received unexpected repsonse format from server")
+		return nil, unexpectedResponse("expected object, got %T", resp)
 	}
 
-	val, ok := obj[terminal]
+	val, ok := obj[path.Last()]
 	if ok {
 		return val, nil
 	} else {
@@ -134,35 +282,125 @@ func (svc *ConfigService) Show(ctx context.Context, path string) (any, error) {
 	}
 }
 
-// Set the configuration at the specified path.
+// Check whether the specified path exists in the configuration
+func (svc *ConfigService) Exists(ctx context.Context, path string) (bool, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return false, err
+	}
+	return svc.ExistsPath(ctx, p)
+}
+
+// Check whether the specified path exists in the configuration
+func (svc *ConfigService) ExistsPath(ctx context.Context, path Path) (bool, error) {
+	resp, err := svc.client.Request(ctx, "retrieve", map[string]any{
+		"op":   "exists",
+		"path": NewPath(path...),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	exists, ok := resp.(bool)
+	if !ok {
+		return false, unexpectedResponse("expected bool, got %T", resp)
+	}
+	return exists, nil
+}
+
+// Return the value of the leaf node at the specified path.
 //
-// If `value` is a string it will be directly set. For lists maps, and any
-// nesting of those types, each individual value will be set in a batch.
-func (svc *ConfigService) Set(ctx context.Context, path string, value any) error {
-	flat, err := Flatten(value)
+// If the node does not exist the returned error matches ErrPathEmpty.
+func (svc *ConfigService) ReturnValue(ctx context.Context, path string) (string, error) {
+	p, err := ParsePath(path)
 	if err != nil {
-		return err
+		return "", err
 	}
+	return svc.ReturnValuePath(ctx, p)
+}
 
-	payload := []map[string]any{}
-	for _, pair := range flat {
-		subpath, value := pair[0], pair[1]
+// Return the value of the leaf node at the specified path. See ReturnValue.
+func (svc *ConfigService) ReturnValuePath(ctx context.Context, path Path) (string, error) {
+	resp, err := svc.client.Request(ctx, "retrieve", map[string]any{
+		"op":   "returnValue",
+		"path": NewPath(path...),
+	})
+	if err != nil {
+		return "", err
+	}
 
-		prefixpath := path
-		if len(prefixpath) > 0 && len(subpath) > 0 {
-			prefixpath += " "
+	if resp == nil {
+		return "", fmt.Errorf("%w: %s", ErrPathEmpty, path)
+	}
+
+	value, ok := resp.(string)
+	if !ok {
+		return "", unexpectedResponse("expected string, got %T", resp)
+	}
+	return value, nil
+}
+
+// Return the values of the multi-value leaf node at the specified path.
+//
+// If the node does not exist an empty list is returned.
+func (svc *ConfigService) ReturnValues(ctx context.Context, path string) ([]string, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ReturnValuesPath(ctx, p)
+}
+
+// Return the values of the multi-value leaf node at the specified path. See ReturnValues.
+func (svc *ConfigService) ReturnValuesPath(ctx context.Context, path Path) ([]string, error) {
+	resp, err := svc.client.Request(ctx, "retrieve", map[string]any{
+		"op":   "returnValues",
+		"path": NewPath(path...),
+	})
+	if err != nil {
+		if errors.Is(err, ErrPathEmpty) {
+			return []string{}, nil
 		}
-		prefixpath += subpath
+		return nil, err
+	}
 
-		payload = append(payload, map[string]any{
-			"op":    "set",
-			"path":  strings.Split(prefixpath, " "),
-			"value": value,
-		})
+	if resp == nil {
+		return []string{}, nil
 	}
 
-	_, err = svc.client.Request(ctx, "configure", payload)
-	return err
+	array, ok := resp.([]any)
+	if !ok {
+		return nil, unexpectedResponse("expected list, got %T", resp)
+	}
+
+	values := []string{}
+	for _, v := range array {
+		value, ok := v.(string)
+		if !ok {
+			return nil, unexpectedResponse("expected string, got %T", v)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Set the configuration at the specified path.
+//
+// If `value` is a string it will be directly set. For lists maps, and any
+// nesting of those types, each individual value will be set in a batch.
+// Structs with `vyos` tags and other typed values are supported as
+// described in FlattenPaths.
+func (svc *ConfigService) Set(ctx context.Context, path string, value any) error {
+	p, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	return svc.SetPath(ctx, p, value)
+}
+
+// Set the configuration at the specified path. See Set.
+func (svc *ConfigService) SetPath(ctx context.Context, path Path, value any) error {
+	return svc.Begin().SetPath(path, value).Commit(ctx)
 }
 
 // Delete values at the specified path.
@@ -171,40 +409,49 @@ func (svc *ConfigService) Set(ctx context.Context, path string, value any) error
 // If `value` is a string it will be directly deleted. For lists maps, and any
 // nesting of those types, each individual value will be deleted in a batch.
 func (svc *ConfigService) Delete(ctx context.Context, path string, value ...any) error {
-	payload := []map[string]any{}
-
-	if value == nil || len(value) == 0 {
-
-		payload = append(payload, map[string]any{
-			"op":   "delete",
-			"path": strings.Split(path, " "),
-		})
-	} else {
-
-		flat, err := Flatten(value)
-		if err != nil {
-			return err
-		}
+	p, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	return svc.DeletePath(ctx, p, value...)
+}
 
-		for _, pair := range flat {
-			subpath, value := pair[0], pair[1]
+// Delete values at the specified path. See Delete.
+func (svc *ConfigService) DeletePath(ctx context.Context, path Path, value ...any) error {
+	return svc.Begin().DeletePath(path, value...).Commit(ctx)
+}
 
-			prefixpath := path
-			if len(prefixpath) > 0 && len(subpath) > 0 {
-				prefixpath += " "
-			}
-			prefixpath += subpath
+// A single operation in a `configure` request
+type Operation struct {
+	Op    string `json:"op"`
+	Path  Path   `json:"path"`
+	Value string `json:"value,omitempty"`
+}
 
-			payload = append(payload, map[string]any{
-				"op":    "delete",
-				"path":  strings.Split(prefixpath, " "),
-				"value": value,
-			})
-		}
+// Marshal the operation, always sending the value of a `comment` operation,
+// since an empty comment clears it
+func (op Operation) MarshalJSON() ([]byte, error) {
+	if op.Op != "comment" {
+		type operation Operation
+		return json.Marshal(operation(op))
 	}
+	return json.Marshal(struct {
+		Op    string `json:"op"`
+		Path  Path   `json:"path"`
+		Value string `json:"value"`
+	}{op.Op, op.Path, op.Value})
+}
 
-	_, err := svc.client.Request(ctx, "configure", payload)
-	return err
+// Send `ops` in a single `configure` request, committing them together.
+//
+// Errors from VyOS are returned as a *ConfigureError identifying the failed
+// operations where possible.
+func (svc *ConfigService) configure(ctx context.Context, ops []Operation) error {
+	_, err := svc.client.Request(ctx, "configure", ops)
+	if err != nil {
+		return newConfigureError(err, ops)
+	}
+	return nil
 }
 
 // Save the running configuration to the default startup configuration
@@ -5,40 +5,55 @@ import (
 	"testing"
 
 	"context"
-	"crypto/tls"
-	"crypto/x509"
-	"io/ioutil"
+	"encoding/json"
 	"net/http"
-	"time"
+	"net/http/httptest"
+	"strings"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func make_client(t *testing.T) (*Client, context.Context) {
-	// Create a CA pool which trusts the VyOS image's selfsigned cert
-	pool := x509.NewCertPool()
-	pem, err := ioutil.ReadFile("../.github/workflows/selfsigned.pem")
-	if err != nil {
-		t.Errorf("failed to read selfsigned cert file: %s", err.Error())
-	}
-	pool.AppendCertsFromPEM(pem)
+	// Trust the VyOS image's selfsigned cert
+	client := New("https://localhost", "vyos", WithCAFile("../.github/workflows/selfsigned.pem"))
+	ctx := context.Background()
 
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			RootCAs: pool,
-		},
-	}
-	http := &http.Client{
-		Timeout:   10 * time.Second,
-		Transport: tr,
-	}
+	return client, ctx
+}
 
-	client := NewWithClient(http, "https://localhost", "vyos")
+// Create a client pointed at a local stub server running `handler`
+func make_stub_client(t *testing.T, handler http.HandlerFunc) (*Client, context.Context) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewWithClient(server.Client(), server.URL, "vyos")
 	ctx := context.Background()
 
 	return client, ctx
 }
 
+// Respond to every request with `status` and the raw json `body`
+func stub_response(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+// Decode each request to the stub server and respond with the result of `handle`
+func stub_api(t *testing.T, handle func(endpoint string, data any) (int, string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var data any
+		if err := json.Unmarshal([]byte(r.FormValue("data")), &data); err != nil {
+			t.Errorf("failed to decode request data: %s", err.Error())
+		}
+
+		status, body := handle(strings.TrimPrefix(r.URL.Path, "/"), data)
+		stub_response(status, body)(w, r)
+	}
+}
+
 func TestUnit_NewClient(t *testing.T) {
 	make_client(t)
 }
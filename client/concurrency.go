@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// How requests which modify the router are ordered against other requests
+type WritePolicy int
+
+const (
+	// Writes run one at a time, while reads run concurrently with each other
+	// and with writes. This is the default.
+	WriteSerialized WritePolicy = iota
+	// Writes run one at a time and wait for in-flight reads to finish, and
+	// reads wait for in-flight writes to finish.
+	WriteExclusive
+	// Writes are not ordered at all, and run concurrently like reads.
+	WriteConcurrent
+)
+
+// Endpoints whose requests modify the router's configuration
+var writeEndpoints = map[string]bool{
+	"configure":   true,
+	"config-file": true,
+}
+
+// Orders concurrent requests made through a Client
+type scheduler struct {
+	policy WritePolicy
+	// Held for writing by writes and for reading by reads under WriteExclusive
+	exclusive rwLock
+	// Held by writes under WriteSerialized
+	writes chan struct{}
+	// Limits the number of requests in flight, nil if unbounded
+	slots chan struct{}
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{policy: WriteSerialized, writes: make(chan struct{}, 1)}
+}
+
+// Wait until a request may run, returning a function which must be called
+// once it is finished. `write` is whether the request modifies the router.
+//
+// The ordering lock is taken before a slot, so that requests waiting their
+// turn don't hold slots other requests could run in.
+func (s *scheduler) acquire(ctx context.Context, write bool) (func(), error) {
+	unlock := func() {}
+	switch {
+	case s.policy == WriteSerialized && write:
+		select {
+		case s.writes <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		unlock = func() { <-s.writes }
+
+	case s.policy == WriteExclusive:
+		if err := s.exclusive.lock(ctx, write); err != nil {
+			return nil, err
+		}
+		unlock = func() { s.exclusive.unlock(write) }
+	}
+
+	if s.slots == nil {
+		return unlock, nil
+	}
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		unlock()
+		return nil, ctx.Err()
+	}
+	return func() { <-s.slots; unlock() }, nil
+}
+
+// A readers-writer lock whose waits can be cancelled. Like sync.RWMutex, a
+// waiting writer keeps new readers from taking the lock.
+type rwLock struct {
+	mutex   sync.Mutex
+	readers int
+	writer  bool
+	// Writers waiting for the lock
+	waiting int
+	// Closed whenever the state changes, to wake waiters
+	changed chan struct{}
+}
+
+func (l *rwLock) lock(ctx context.Context, write bool) error {
+	l.mutex.Lock()
+	if write {
+		l.waiting++
+	}
+	for {
+		if !l.writer && (write && l.readers == 0 || !write && l.waiting == 0) {
+			if write {
+				l.waiting--
+				l.writer = true
+			} else {
+				l.readers++
+			}
+			l.mutex.Unlock()
+			return nil
+		}
+
+		if l.changed == nil {
+			l.changed = make(chan struct{})
+		}
+		changed := l.changed
+		l.mutex.Unlock()
+
+		select {
+		case <-changed:
+			l.mutex.Lock()
+		case <-ctx.Done():
+			l.mutex.Lock()
+			if write {
+				// Readers held back by this writer may go ahead
+				l.waiting--
+				l.broadcast()
+			}
+			l.mutex.Unlock()
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *rwLock) unlock(write bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if write {
+		l.writer = false
+	} else {
+		l.readers--
+	}
+	l.broadcast()
+}
+
+// Wake every waiter. Must be called with the mutex held.
+func (l *rwLock) broadcast() {
+	if l.changed != nil {
+		close(l.changed)
+		l.changed = nil
+	}
+}
+
+// Set how requests which modify the router are ordered against other
+// requests. These are requests to `configure`, requests to `config-file` and
+// GraphQL mutations. Defaults to WriteSerialized.
+//
+// Must be called before the client is used.
+func (c *Client) SetWritePolicy(policy WritePolicy) {
+	c.scheduler.policy = policy
+}
+
+// Limit the number of requests in flight at once. Zero or less removes the
+// limit, which is the default.
+//
+// Must be called before the client is used.
+func (c *Client) SetMaxConcurrency(n int) {
+	if n <= 0 {
+		c.scheduler.slots = nil
+	} else {
+		c.scheduler.slots = make(chan struct{}, n)
+	}
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tracks the maximum number of requests in flight at the stub server
+type inflight struct {
+	reads, writes, total          int64
+	maxReads, maxWrites, maxTotal int64
+	overlap                       int64
+}
+
+func (f *inflight) enter(counter *int64, max *int64) {
+	n := atomic.AddInt64(counter, 1)
+	for {
+		m := atomic.LoadInt64(max)
+		if n <= m || atomic.CompareAndSwapInt64(max, m, n) {
+			break
+		}
+	}
+}
+
+func stub_inflight(t *testing.T, f *inflight) http.HandlerFunc {
+	return stub_api(t, func(endpoint string, data any) (int, string) {
+		write := endpoint == "configure"
+		f.enter(&f.total, &f.maxTotal)
+		if write {
+			f.enter(&f.writes, &f.maxWrites)
+		} else {
+			f.enter(&f.reads, &f.maxReads)
+		}
+		if atomic.LoadInt64(&f.reads) > 0 && atomic.LoadInt64(&f.writes) > 0 {
+			atomic.StoreInt64(&f.overlap, 1)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		if write {
+			atomic.AddInt64(&f.writes, -1)
+		} else {
+			atomic.AddInt64(&f.reads, -1)
+		}
+		atomic.AddInt64(&f.total, -1)
+		return http.StatusOK, `{"success": true, "data": {"host-name": "vyos"}, "error": null}`
+	})
+}
+
+// Issue `n` reads and `n` writes from separate goroutines
+func hammer(t *testing.T, client *Client, ctx context.Context, n int) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := client.Config.Show(ctx, "system host-name")
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			err := client.Config.Set(ctx, "system host-name", "vyos")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestUnit_Concurrency_Serialized(t *testing.T) {
+	f := &inflight{}
+	client, ctx := make_stub_client(t, stub_inflight(t, f))
+
+	hammer(t, client, ctx, 8)
+	assert.Greater(t, f.maxReads, int64(1), "expected reads to run concurrently")
+	assert.Equal(t, int64(1), f.maxWrites, "expected writes to be serialized")
+	assert.Equal(t, int64(1), f.overlap, "expected reads to overlap writes")
+}
+
+func TestUnit_Concurrency_Exclusive(t *testing.T) {
+	f := &inflight{}
+	client, ctx := make_stub_client(t, stub_inflight(t, f))
+	client.SetWritePolicy(WriteExclusive)
+
+	hammer(t, client, ctx, 8)
+	assert.Greater(t, f.maxReads, int64(1), "expected reads to run concurrently")
+	assert.Equal(t, int64(1), f.maxWrites, "expected writes to be serialized")
+	assert.Equal(t, int64(0), f.overlap, "expected reads to never overlap writes")
+}
+
+func TestUnit_Concurrency_Concurrent(t *testing.T) {
+	f := &inflight{}
+	client, ctx := make_stub_client(t, stub_inflight(t, f))
+	client.SetWritePolicy(WriteConcurrent)
+
+	hammer(t, client, ctx, 8)
+	assert.Greater(t, f.maxWrites, int64(1), "expected writes to run concurrently")
+}
+
+func TestUnit_Concurrency_Bounded(t *testing.T) {
+	f := &inflight{}
+	client, ctx := make_stub_client(t, stub_inflight(t, f))
+	client.SetWritePolicy(WriteConcurrent)
+	client.SetMaxConcurrency(3)
+
+	hammer(t, client, ctx, 8)
+	assert.LessOrEqual(t, f.maxTotal, int64(3), "expected at most 3 requests in flight")
+	assert.Greater(t, f.maxTotal, int64(1), "expected requests to run concurrently")
+}
+
+func TestUnit_Concurrency_BoundedSerialized(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_api(t, func(endpoint string, data any) (int, string) {
+		if endpoint == "configure" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return http.StatusOK, `{"success": true, "data": {"host-name": "vyos"}, "error": null}`
+	}))
+	client.SetMaxConcurrency(2)
+
+	// Writes queued behind the first must not hold the remaining slot, so a
+	// read finishes well before the first write does
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, client.Config.Set(ctx, "system host-name", "vyos"))
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	readCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err := client.Config.Show(readCtx, "system host-name")
+	assert.NoError(t, err)
+
+	wg.Wait()
+}
+
+func TestUnit_Concurrency_CancelQueuedWrite(t *testing.T) {
+	for _, policy := range []WritePolicy{WriteSerialized, WriteExclusive} {
+		f := &inflight{}
+		client, _ := make_stub_client(t, stub_inflight(t, f))
+		client.SetWritePolicy(policy)
+
+		// Hold the write lock
+		release, err := client.scheduler.acquire(context.Background(), true)
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		err = client.Config.Set(ctx, "system host-name", "vyos")
+		cancel()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// The cancelled write no longer holds back anything
+		release()
+		assert.NoError(t, client.Config.Set(context.Background(), "system host-name", "vyos"))
+		_, err = client.Config.Show(context.Background(), "system host-name")
+		assert.NoError(t, err)
+	}
+}
+
+func TestUnit_Concurrency_Cancel(t *testing.T) {
+	f := &inflight{}
+	client, _ := make_stub_client(t, stub_inflight(t, f))
+	client.SetMaxConcurrency(1)
+
+	// Hold the only slot
+	release, err := client.scheduler.acquire(context.Background(), false)
+	assert.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.Config.Show(ctx, "system host-name")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
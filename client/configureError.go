@@ -0,0 +1,201 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A single failed operation within a `configure` batch
+type OperationError struct {
+	// Position of the operation in the batch
+	Index     int
+	Operation Operation
+	// Validation message produced by VyOS for this operation
+	Message string
+}
+
+func (e OperationError) Error() string {
+	path := e.Operation.Path
+	if e.Operation.Value != "" {
+		path = path.Append(e.Operation.Value)
+	}
+	return fmt.Sprintf("operation %d (%s %s): %s", e.Index, e.Operation.Op, path, e.Message)
+}
+
+// Returned when a `configure` batch or a GraphQL mutation is rejected by VyOS.
+//
+// VyOS only reports a single error message for the whole batch, so Failed is
+// filled on a best-effort basis by matching the configuration paths and
+// values mentioned in the message against the operations that were sent. It
+// is empty if no operation could be identified.
+type ConfigureError struct {
+	// The operations that were sent, empty for GraphQL mutations
+	Operations []Operation
+	// The operations identified as having failed
+	Failed []OperationError
+	// The underlying error returned by the API
+	Err *APIError
+}
+
+func (e *ConfigureError) Error() string {
+	if len(e.Failed) == 0 {
+		return e.Err.Error()
+	}
+
+	lines := []string{}
+	for _, failed := range e.Failed {
+		lines = append(lines, failed.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *ConfigureError) Unwrap() error {
+	return e.Err
+}
+
+// Wrap an error returned for a `configure` request of `ops`
+func newConfigureError(err error, ops []Operation) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message == "" {
+		return err
+	}
+
+	return &ConfigureError{
+		Operations: ops,
+		Failed:     matchOperationErrors(apiErr.Message, ops),
+		Err:        apiErr,
+	}
+}
+
+// `[ interfaces ethernet eth0 ]` heading a commit error
+var configSectionPattern = regexp.MustCompile(`^\[\s*(?P<path>[^\[\]]+?)\s*\]$`)
+
+// `[[interfaces ethernet eth0]] failed` ending a commit error
+var configFailedPattern = regexp.MustCompile(`^\[\[\s*(?P<path>[^\[\]]+?)\s*\]\] failed`)
+
+// `Configuration path: [interfaces ethernet eth0 foo] is not valid` from set and delete
+var configPathPattern = regexp.MustCompile(`\[\s*(?P<path>[^\[\]]+?)\s*\]`)
+
+// A path mentioned in an error message, with the text describing it
+type messageRef struct {
+	path    Path
+	message string
+}
+
+// Split an error message from VyOS into the paths it mentions
+func parseMessageRefs(message string) []messageRef {
+	refs := []messageRef{}
+
+	var section *messageRef
+	sectionLines := []string{}
+
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if match, ok := matchStringNamed(configSectionPattern, line); ok {
+			path, err := ParsePath(match["path"])
+			if err == nil {
+				section = &messageRef{path, ""}
+				sectionLines = []string{}
+			}
+			continue
+		}
+
+		if match, ok := matchStringNamed(configFailedPattern, line); ok {
+			path, err := ParsePath(match["path"])
+			if err != nil {
+				continue
+			}
+
+			text := line
+			if section != nil && comparePaths(section.path, path) == 0 && len(sectionLines) > 0 {
+				text = strings.Join(sectionLines, "\n")
+			}
+			refs = append(refs, messageRef{path, text})
+			section = nil
+			continue
+		}
+
+		if section != nil {
+			sectionLines = append(sectionLines, line)
+			continue
+		}
+
+		if match, ok := matchStringNamed(configPathPattern, line); ok {
+			path, err := ParsePath(match["path"])
+			if err == nil {
+				refs = append(refs, messageRef{path, line})
+			}
+		}
+	}
+
+	return refs
+}
+
+// Identify which of `ops` an error message refers to
+func matchOperationErrors(message string, ops []Operation) []OperationError {
+	failed := []OperationError{}
+	found := map[int]bool{}
+
+	add := func(i int, text string) {
+		if !found[i] {
+			found[i] = true
+			failed = append(failed, OperationError{i, ops[i], text})
+		}
+	}
+
+	// Match by the paths mentioned in the message. Set and delete errors
+	// mention the full path including the value, while commit errors mention
+	// the node which failed to commit.
+	for _, ref := range parseMessageRefs(message) {
+		exact := false
+		for i, op := range ops {
+			if comparePaths(op.Path, ref.path) == 0 || comparePaths(op.Path.Append(op.Value), ref.path) == 0 {
+				add(i, ref.message)
+				exact = true
+			}
+		}
+		if exact {
+			continue
+		}
+		for i, op := range ops {
+			if op.Path.HasPrefix(ref.path) {
+				add(i, ref.message)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+
+	text := strings.TrimSpace(message)
+
+	// Otherwise match by a value mentioned in the message
+	candidates := []int{}
+	for i, op := range ops {
+		if op.Value != "" && containsWord(message, op.Value) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 1 {
+		add(candidates[0], text)
+		return failed
+	}
+
+	// A batch of one can only fail in one place
+	if len(ops) == 1 {
+		add(0, text)
+	}
+	return failed
+}
+
+// Check whether `word` appears in `s` delimited by whitespace, quotes or brackets
+func containsWord(s string, word string) bool {
+	pattern := `(^|[\s'"\[])` + regexp.QuoteMeta(word) + `($|[\s'"\],.:])`
+	return regexp.MustCompile(pattern).MatchString(s)
+}
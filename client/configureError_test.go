@@ -0,0 +1,83 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testBatch = []Operation{
+	{"set", Path{"interfaces", "ethernet", "eth0", "address"}, "10.0.0.1/24"},
+	{"set", Path{"interfaces", "ethernet", "eth0", "vrf"}, "BLUE"},
+	{"set", Path{"interfaces", "ethernet", "eth1", "mtu"}, "99999"},
+	{"set", Path{"system", "host-name"}, "router"},
+}
+
+func TestUnit_MatchOperationErrors_SetPath(t *testing.T) {
+	failed := matchOperationErrors(
+		"Configuration path: [interfaces ethernet eth0 address 10.0.0.1/24] is not valid\nSet failed\n",
+		testBatch,
+	)
+	assert.Equal(t, []OperationError{{
+		0,
+		testBatch[0],
+		"Configuration path: [interfaces ethernet eth0 address 10.0.0.1/24] is not valid",
+	}}, failed)
+}
+
+func TestUnit_MatchOperationErrors_Commit(t *testing.T) {
+	failed := matchOperationErrors(
+		"\n[ interfaces ethernet eth0 ]\nVRF \"BLUE\" does not exist!\n\n[[interfaces ethernet eth0]] failed\nCommit failed\n",
+		testBatch,
+	)
+
+	// Every operation under the failed node is reported
+	assert.Equal(t, []OperationError{
+		{0, testBatch[0], `VRF "BLUE" does not exist!`},
+		{1, testBatch[1], `VRF "BLUE" does not exist!`},
+	}, failed)
+}
+
+func TestUnit_MatchOperationErrors_Value(t *testing.T) {
+	message := "\n\n  Number 99999 is not in any of accepted ranges\n  Value validation failed\n  Set failed\n\n"
+	failed := matchOperationErrors(message, testBatch)
+	assert.Len(t, failed, 1)
+	assert.Equal(t, 2, failed[0].Index)
+	assert.Equal(t, "Number 99999 is not in any of accepted ranges\n  Value validation failed\n  Set failed", failed[0].Message)
+}
+
+func TestUnit_MatchOperationErrors_Unknown(t *testing.T) {
+	message := "Invalid value\nValue validation failed\nSet failed"
+	assert.Empty(t, matchOperationErrors(message, testBatch))
+
+	failed := matchOperationErrors(message, testBatch[3:])
+	assert.Equal(t, []OperationError{{0, testBatch[3], message}}, failed)
+}
+
+func TestUnit_Config_ConfigureError(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_response(
+		http.StatusBadRequest,
+		`{"success": false, "error": "Configuration path: [system host-name router] is not valid\nSet failed", "data": null}`,
+	))
+
+	err := client.Config.Begin().Add(testBatch...).Commit(ctx)
+
+	var configureErr *ConfigureError
+	assert.True(t, errors.As(err, &configureErr), "expected a *ConfigureError")
+	assert.Equal(t, testBatch, configureErr.Operations)
+	assert.Len(t, configureErr.Failed, 1)
+	assert.Equal(t, 3, configureErr.Failed[0].Index)
+	assert.EqualError(t, err, "operation 3 (set system host-name router): Configuration path: [system host-name router] is not valid")
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr), "expected an *APIError")
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+
+	// Errors without a VyOS message are passed through
+	client, ctx = make_stub_client(t, stub_response(http.StatusNotFound, `{"detail": "Not Found"}`))
+	err = client.Config.Set(ctx, "system host-name", "router")
+	assert.True(t, errors.As(err, &apiErr), "expected an *APIError")
+	assert.False(t, errors.As(err, &configureErr), "expected no *ConfigureError")
+}
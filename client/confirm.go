@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// A check run after a commit-confirm to decide whether to confirm it
+type HealthCheck func(ctx context.Context) error
+
+// Returned when a commit-confirm was left unconfirmed because its health
+// check failed. The router reverts the changes once the timeout expires.
+type UnconfirmedError struct {
+	// Minutes until the router reverts the changes
+	Minutes int
+	// Error returned by the health check
+	Err error
+}
+
+func (e *UnconfirmedError) Error() string {
+	return fmt.Sprintf(
+		"health check failed, changes will be reverted in %d minutes: %s",
+		e.Minutes,
+		e.Err.Error(),
+	)
+}
+
+func (e *UnconfirmedError) Unwrap() error {
+	return e.Err
+}
+
+// Send `ops` in a single `configure` request committed with commit-confirm.
+//
+// Unless Confirm is called within `minutes`, the router reverts the changes
+// on its own.
+func (svc *ConfigService) CommitConfirm(ctx context.Context, minutes int, ops []Operation) error {
+	if minutes < 1 {
+		return fmt.Errorf("commit-confirm timeout must be at least 1 minute, got %d", minutes)
+	}
+
+	_, err := svc.client.Request(ctx, "configure", map[string]any{
+		"commands":     ops,
+		"confirm_time": minutes,
+	})
+	if err != nil {
+		return newConfigureError(err, ops)
+	}
+	return nil
+}
+
+// Confirm a pending commit-confirm, keeping its changes
+func (svc *ConfigService) Confirm(ctx context.Context) error {
+	_, err := svc.client.Request(ctx, "configure", map[string]any{
+		"op": "confirm",
+	})
+	return err
+}
+
+// Send `ops` with commit-confirm, then run `check` and only confirm the
+// commit if it passes.
+//
+// If `check` is nil, Client.Ping is used to make sure the API is still
+// reachable. If the check fails an *UnconfirmedError is returned and the
+// router is left to revert the changes once `minutes` expire.
+func (svc *ConfigService) CommitConfirmWith(ctx context.Context, minutes int, ops []Operation, check HealthCheck) error {
+	if check == nil {
+		check = svc.client.Ping
+	}
+
+	err := svc.CommitConfirm(ctx, minutes, ops)
+	if err != nil {
+		return err
+	}
+
+	err = check(ctx)
+	if err != nil {
+		return &UnconfirmedError{minutes, err}
+	}
+
+	return svc.Confirm(ctx)
+}
+
+// Like Apply, but commit the plan with CommitConfirmWith so that it is
+// reverted unless `check` passes afterwards.
+func (svc *ConfigService) ApplyConfirm(ctx context.Context, path string, desired any, minutes int, check HealthCheck) (Plan, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return Plan{}, err
+	}
+	return svc.ApplyConfirmPath(ctx, p, desired, minutes, check)
+}
+
+// Reconcile the configuration at the specified path, reverting unless `check`
+// passes. See ApplyConfirm.
+func (svc *ConfigService) ApplyConfirmPath(ctx context.Context, path Path, desired any, minutes int, check HealthCheck) (Plan, error) {
+	plan, err := svc.PlanPath(ctx, path, desired)
+	if err != nil || plan.IsEmpty() {
+		return plan, err
+	}
+
+	return plan, svc.CommitConfirmWith(ctx, minutes, plan.Operations(), check)
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A stub recording each `configure` payload, answering retrieves with `tree`
+func stub_confirm(t *testing.T, requests *[]any, tree string) http.HandlerFunc {
+	return stub_api(t, func(endpoint string, data any) (int, string) {
+		if endpoint == "retrieve" {
+			return http.StatusOK, `{"success": true, "data": ` + tree + `, "error": null}`
+		}
+		*requests = append(*requests, data)
+		return http.StatusOK, `{"success": true, "data": null, "error": null}`
+	})
+}
+
+func TestUnit_Config_CommitConfirm(t *testing.T) {
+	var requests []any
+	client, ctx := make_stub_client(t, stub_confirm(t, &requests, "true"))
+
+	ops := []Operation{{"set", Path{"system", "host-name"}, "router"}}
+	err := client.Config.CommitConfirm(ctx, 5, ops)
+	assert.NoError(t, err)
+	err = client.Config.Confirm(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []any{
+		map[string]any{
+			"commands": []any{
+				map[string]any{"op": "set", "path": []any{"system", "host-name"}, "value": "router"},
+			},
+			"confirm_time": float64(5),
+		},
+		map[string]any{"op": "confirm"},
+	}, requests)
+
+	err = client.Config.CommitConfirm(ctx, 0, ops)
+	assert.Error(t, err, "expected error on zero timeout")
+}
+
+func TestUnit_Config_CommitConfirmWith(t *testing.T) {
+	var requests []any
+	client, ctx := make_stub_client(t, stub_confirm(t, &requests, "true"))
+	ops := []Operation{{"delete", Path{"firewall"}, ""}}
+
+	// Passing check sends the confirm
+	err := client.Config.CommitConfirmWith(ctx, 2, ops, nil)
+	assert.NoError(t, err)
+	assert.Len(t, requests, 2)
+	assert.Equal(t, map[string]any{"op": "confirm"}, requests[1])
+
+	// Failing check leaves the commit unconfirmed
+	requests = nil
+	lockedOut := errors.New("locked out")
+	err = client.Config.CommitConfirmWith(ctx, 2, ops, func(ctx context.Context) error {
+		return lockedOut
+	})
+	assert.ErrorIs(t, err, lockedOut)
+	var unconfirmed *UnconfirmedError
+	assert.True(t, errors.As(err, &unconfirmed), "expected an *UnconfirmedError")
+	assert.Equal(t, 2, unconfirmed.Minutes)
+	assert.Len(t, requests, 1)
+}
+
+func TestUnit_Config_ApplyConfirm(t *testing.T) {
+	var requests []any
+	client, ctx := make_stub_client(t, stub_confirm(t, &requests, `{"host-name": "vyos"}`))
+
+	plan, err := client.Config.ApplyConfirm(ctx, "system host-name", "router", 10, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "+set system host-name 'router'\n", plan.String())
+	assert.Len(t, requests, 2)
+}
+
+func TestUnit_Config_ApplyConfirmPath(t *testing.T) {
+	var requests []any
+	client, ctx := make_stub_client(t, stub_confirm(t, &requests, `{"description": "old"}`))
+
+	path := Path{"interfaces", "ethernet", "eth0"}
+	plan, err := client.Config.ApplyConfirmPath(ctx, path, map[string]any{"description": "uplink to core"}, 5, nil)
+	assert.NoError(t, err)
+	assert.Len(t, plan.Changes, 1)
+	assert.Equal(t, []any{
+		map[string]any{
+			"commands": []any{
+				map[string]any{"op": "set", "path": []any{"interfaces", "ethernet", "eth0", "description"}, "value": "uplink to core"},
+			},
+			"confirm_time": float64(5),
+		},
+		map[string]any{"op": "confirm"},
+	}, requests)
+
+	// Nothing is sent when the configuration already matches
+	requests = nil
+	plan, err = client.Config.ApplyConfirmPath(ctx, path, map[string]any{"description": "old"}, 5, nil)
+	assert.NoError(t, err)
+	assert.True(t, plan.IsEmpty())
+	assert.Empty(t, requests)
+}
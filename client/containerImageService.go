@@ -2,8 +2,6 @@ package client
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"regexp"
 	"strings"
 )
@@ -44,7 +42,7 @@ func (svc *ContainerImageService) Show(ctx context.Context) ([]ContainerImage, e
 
 	data, ok := resp.(string)
 	if !ok {
-		return nil, errors.New("received unexpected repsonse format from server")
+		return nil, unexpectedResponse("expected string, got %T", resp)
 	}
 
 	return parseImages(data)
@@ -75,7 +73,7 @@ func parseImages(data string) ([]ContainerImage, error) {
 
 		match, ok := matchStringNamed(imageLinePattern, line)
 		if !ok {
-			return nil, fmt.Errorf("invalid image in response from vyos api:\n%s", line)
+			return nil, unexpectedResponse("invalid image in response from vyos api:\n%s", line)
 		}
 
 		images = append(images, ContainerImage{
@@ -86,7 +84,7 @@ func parseImages(data string) ([]ContainerImage, error) {
 	}
 
 	if !foundHeader {
-		return nil, fmt.Errorf("could not find expected container image header in response from vyos api:\n%s", data)
+		return nil, unexpectedResponse("could not find expected container image header in response from vyos api:\n%s", data)
 	}
 	return images, nil
 }
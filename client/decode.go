@@ -0,0 +1,282 @@
+package client
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Return the configuration tree at the specified path decoded into `out`.
+//
+// See Unmarshal for how the tree is mapped onto Go types.
+func ShowInto[T any](ctx context.Context, svc *ConfigService, path string, out *T) error {
+	return svc.Decode(ctx, path, out)
+}
+
+// Decode the configuration tree at the specified path into `v`, which must be
+// a non-nil pointer. If the path does not exist `v` is left untouched.
+//
+// See Unmarshal for how the tree is mapped onto Go types.
+func (svc *ConfigService) Decode(ctx context.Context, path string, v any) error {
+	p, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	return svc.DecodePath(ctx, p, v)
+}
+
+// Decode the configuration tree at the specified path into `v`. See Decode.
+func (svc *ConfigService) DecodePath(ctx context.Context, path Path, v any) error {
+	tree, err := svc.ShowPath(ctx, path)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(tree, v)
+}
+
+// Decode a configuration tree, as returned by Show, into `v`, which must be a
+// non-nil pointer.
+//
+// Struct fields are matched to nodes by their `vyos:"name"` tag, and fields
+// without a tag or tagged with "-" are ignored. Untagged embedded structs have
+// their fields decoded as if they were part of the outer struct.
+//
+// Nodes are mapped as follows:
+//   - leaves decode into strings, ints, uints, floats, time.Duration (as
+//     seconds), or any type implementing encoding.TextUnmarshaler
+//   - multi-value leaves decode into slices, and a leaf holding a single value
+//     decodes into a slice of length one
+//   - tag nodes decode into maps keyed by the tag value
+//   - valueless nodes decode into bool, which is true whenever they are present
+//   - anything decodes into an `any`, which receives the raw tree
+func Unmarshal(tree any, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("unmarshal target must be a non-nil pointer, got %T", v)
+	}
+	return decode(Path{}, tree, rv.Elem())
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func decode(path Path, tree any, v reflect.Value) error {
+	if tree == nil {
+		return nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decode(path, tree, v.Elem())
+	}
+
+	if v.Type() == durationType {
+		s, ok := tree.(string)
+		if !ok {
+			return decodeError(path, tree, v)
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.SetInt(int64(time.Duration(n) * time.Second))
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		s, ok := tree.(string)
+		if !ok {
+			return decodeError(path, tree, v)
+		}
+		err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return decodeError(path, tree, v)
+		}
+		v.Set(reflect.ValueOf(tree))
+
+	case reflect.Bool:
+		switch tree := tree.(type) {
+		case map[string]any:
+			// Valueless nodes are present as an empty object
+			v.SetBool(true)
+		case string:
+			b, err := strconv.ParseBool(tree)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			v.SetBool(b)
+		default:
+			return decodeError(path, tree, v)
+		}
+
+	case reflect.String:
+		s, ok := tree.(string)
+		if !ok {
+			return decodeError(path, tree, v)
+		}
+		v.SetString(s)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s, ok := tree.(string)
+		if !ok {
+			return decodeError(path, tree, v)
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s, ok := tree.(string)
+		if !ok {
+			return decodeError(path, tree, v)
+		}
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		s, ok := tree.(string)
+		if !ok {
+			return decodeError(path, tree, v)
+		}
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.SetFloat(n)
+
+	case reflect.Slice:
+		// A multi-value leaf with a single value is returned as a plain value
+		array, ok := tree.([]any)
+		if !ok {
+			array = []any{tree}
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(array), len(array))
+		for i, elem := range array {
+			err := decode(path, elem, slice.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+
+	case reflect.Map:
+		obj, ok := tree.(map[string]any)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return decodeError(path, tree, v)
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(obj)))
+		}
+		for _, k := range sortedKeys(obj) {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			err := decode(path.Append(k), obj[k], elem)
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), elem)
+		}
+
+	case reflect.Struct:
+		obj, ok := tree.(map[string]any)
+		if !ok {
+			return decodeError(path, tree, v)
+		}
+
+		for _, field := range structFields(v, true) {
+			child, ok := obj[field.name]
+			if !ok {
+				continue
+			}
+			err := decode(path.Append(field.name), child, field.value)
+			if err != nil {
+				return err
+			}
+		}
+
+	default:
+		return decodeError(path, tree, v)
+	}
+
+	return nil
+}
+
+func decodeError(path Path, tree any, v reflect.Value) error {
+	return fmt.Errorf("%s: cannot decode %T into %s", path, tree, v.Type())
+}
+
+// A struct field mapped to a configuration node by its `vyos` tag
+type taggedField struct {
+	name      string
+	omitempty bool
+	value     reflect.Value
+}
+
+// Return the tagged fields of the struct `v`, including those of untagged
+// embedded structs. Nil embedded struct pointers are allocated if `alloc` is
+// set, and skipped otherwise.
+func structFields(v reflect.Value, alloc bool) []taggedField {
+	fields := []taggedField{}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+
+		tag, tagged := field.Tag.Lookup("vyos")
+		if !tagged {
+			if !field.Anonymous {
+				continue
+			}
+
+			if value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					if !alloc || !value.CanSet() {
+						continue
+					}
+					value.Set(reflect.New(value.Type().Elem()))
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				fields = append(fields, structFields(value, alloc)...)
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" || name == "" {
+			continue
+		}
+
+		fields = append(fields, taggedField{
+			name:      name,
+			omitempty: opts == "omitempty",
+			value:     value,
+		})
+	}
+
+	return fields
+}
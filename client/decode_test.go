@@ -0,0 +1,129 @@
+package client
+
+import (
+	"net/http"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSystem struct {
+	HostName    string       `vyos:"host-name"`
+	NameServers []string     `vyos:"name-server"`
+	Options     *testOptions `vyos:"option"`
+	Ignored     string       `vyos:"-"`
+	Untagged    string
+	Login       testLogin      `vyos:"login"`
+	Syslog      map[string]any `vyos:"syslog"`
+	Console     map[string]struct {
+		Speed int `vyos:"speed"`
+	} `vyos:"console"`
+}
+
+type testOptions struct {
+	RebootOnPanic bool `vyos:"reboot-on-panic"`
+	StartupBeep   bool `vyos:"startup-beep"`
+}
+
+type testLogin struct {
+	Users map[string]testUser `vyos:"user"`
+}
+
+type testUser struct {
+	FullName string `vyos:"full-name"`
+	Uid      uint16 `vyos:"uid"`
+}
+
+func TestUnit_Unmarshal_Struct(t *testing.T) {
+	tree := map[string]any{
+		"host-name":   "vyos",
+		"name-server": []any{"1.1.1.1", "1.0.0.1"},
+		"option": map[string]any{
+			"reboot-on-panic": map[string]any{},
+		},
+		"login": map[string]any{
+			"user": map[string]any{
+				"vyos":  map[string]any{"full-name": "VyOS User", "uid": "1000"},
+				"admin": map[string]any{},
+			},
+		},
+		"syslog": map[string]any{"global": map[string]any{}},
+		"console": map[string]any{
+			"device": map[string]any{"speed": "115200"},
+		},
+		"Untagged": "foo",
+	}
+
+	var system testSystem
+	err := Unmarshal(tree, &system)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "vyos", system.HostName)
+	assert.Equal(t, []string{"1.1.1.1", "1.0.0.1"}, system.NameServers)
+	assert.True(t, system.Options.RebootOnPanic)
+	assert.False(t, system.Options.StartupBeep)
+	assert.Equal(t, "", system.Untagged)
+	assert.Equal(t, testUser{"VyOS User", 1000}, system.Login.Users["vyos"])
+	assert.Equal(t, testUser{}, system.Login.Users["admin"])
+	assert.Equal(t, map[string]any{"global": map[string]any{}}, system.Syslog)
+	assert.Equal(t, 115200, system.Console["device"].Speed)
+}
+
+func TestUnit_Unmarshal_SingleValueList(t *testing.T) {
+	var system testSystem
+	err := Unmarshal(map[string]any{"name-server": "1.1.1.1"}, &system)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.1.1.1"}, system.NameServers)
+}
+
+func TestUnit_Unmarshal_TextUnmarshaler(t *testing.T) {
+	var iface struct {
+		Address []netip.Prefix `vyos:"address"`
+	}
+	err := Unmarshal(map[string]any{"address": []any{"10.0.0.1/24", "2001:db8::1/64"}}, &iface)
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.1/24"),
+		netip.MustParsePrefix("2001:db8::1/64"),
+	}, iface.Address)
+}
+
+func TestUnit_Unmarshal_Embedded(t *testing.T) {
+	type base struct {
+		Description string `vyos:"description"`
+	}
+	var iface struct {
+		base
+		Mtu int `vyos:"mtu"`
+	}
+	err := Unmarshal(map[string]any{"mtu": "1500", "description": "uplink"}, &iface)
+	assert.NoError(t, err)
+	assert.Equal(t, 1500, iface.Mtu)
+	assert.Equal(t, "uplink", iface.Description)
+}
+
+func TestUnit_Unmarshal_Errors(t *testing.T) {
+	var system testSystem
+	assert.Error(t, Unmarshal(map[string]any{}, system), "expected error on non-pointer")
+
+	err := Unmarshal(map[string]any{"host-name": []any{"a", "b"}}, &system)
+	assert.ErrorContains(t, err, "host-name: cannot decode []interface {} into string")
+
+	err = Unmarshal(map[string]any{"console": map[string]any{"device": map[string]any{"speed": "fast"}}}, &system)
+	assert.ErrorContains(t, err, "console device speed")
+
+	err = Unmarshal("foo", &system)
+	assert.Error(t, err, "expected error decoding string into struct")
+}
+
+func TestUnit_Config_ShowInto(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_api(t, func(endpoint string, data any) (int, string) {
+		return http.StatusOK, `{"success": true, "data": {"option": {"startup-beep": {}}}, "error": null}`
+	}))
+
+	var options testOptions
+	err := ShowInto(ctx, client.Config, "system option", &options)
+	assert.NoError(t, err)
+	assert.Equal(t, testOptions{StartupBeep: true}, options)
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"net/http"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// VyOS 1.3
+const leasesOutputLegacy = `IP address      Hardware address    State    Lease start          Lease expiration     Remaining   Pool       Hostname
+--------------  ------------------  -------  -------------------  -------------------  ----------  ---------  ----------
+192.0.2.101     00:53:00:11:22:33   active   2019/12/05 14:24:23  2019/12/06 02:24:23  11:59:59    LAN        client1
+192.0.2.102     00:53:00:11:22:44   expired  2019/12/04 10:00:00  2019/12/04 22:00:00  0:00:00     LAN
+`
+
+// VyOS 1.4
+const leasesOutput14 = `IP Address      MAC address        State    Lease start          Lease expiration     Remaining    Pool         Hostname      Origin
+--------------  -----------------  -------  -------------------  -------------------  -----------  -----------  ------------  --------
+192.168.11.134  00:50:79:66:68:05  active   2023/11/29 09:51:05  2023/11/29 10:21:05  0:24:10      LAN          VM-1          local
+192.168.11.135  00:50:79:66:68:06  free     -                    -                                 LAN                        remote
+10.0.0.5        00:50:79:66:68:07  active   2023/11/29 09:00:00  2023/11/30 09:00:00  23:32:55     GUEST-WIFI   phone         local
+`
+
+func TestUnit_DHCP_ParseLegacy(t *testing.T) {
+	leases, err := parseLeases(leasesOutputLegacy)
+	assert.NoError(t, err)
+	assert.Equal(t, []DHCPLease{
+		{
+			IP:       netip.MustParseAddr("192.0.2.101"),
+			MAC:      "00:53:00:11:22:33",
+			Hostname: "client1",
+			State:    "active",
+			Start:    time.Date(2019, 12, 5, 14, 24, 23, 0, time.UTC),
+			Expiry:   time.Date(2019, 12, 6, 2, 24, 23, 0, time.UTC),
+			Pool:     "LAN",
+		},
+		{
+			IP:     netip.MustParseAddr("192.0.2.102"),
+			MAC:    "00:53:00:11:22:44",
+			State:  "expired",
+			Start:  time.Date(2019, 12, 4, 10, 0, 0, 0, time.UTC),
+			Expiry: time.Date(2019, 12, 4, 22, 0, 0, 0, time.UTC),
+			Pool:   "LAN",
+		},
+	}, leases)
+}
+
+func TestUnit_DHCP_Parse14(t *testing.T) {
+	leases, err := parseLeases(leasesOutput14)
+	assert.NoError(t, err)
+	assert.Len(t, leases, 3)
+
+	assert.Equal(t, DHCPLease{
+		IP:       netip.MustParseAddr("192.168.11.134"),
+		MAC:      "00:50:79:66:68:05",
+		Hostname: "VM-1",
+		State:    "active",
+		Start:    time.Date(2023, 11, 29, 9, 51, 5, 0, time.UTC),
+		Expiry:   time.Date(2023, 11, 29, 10, 21, 5, 0, time.UTC),
+		Pool:     "LAN",
+	}, leases[0])
+
+	// Without times or hostname
+	assert.Equal(t, DHCPLease{
+		IP:    netip.MustParseAddr("192.168.11.135"),
+		MAC:   "00:50:79:66:68:06",
+		State: "free",
+		Pool:  "LAN",
+	}, leases[1])
+
+	assert.Equal(t, "GUEST-WIFI", leases[2].Pool)
+	assert.Equal(t, "phone", leases[2].Hostname)
+}
+
+func TestUnit_DHCP_ParseInvalid(t *testing.T) {
+	_, err := parseLeases("DHCP server is not configured\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+
+	header := strings.Join(strings.SplitAfter(leasesOutput14, "\n")[:2], "")
+	leases, err := parseLeases(header)
+	assert.NoError(t, err)
+	assert.Empty(t, leases)
+
+	_, err = parseLeases(header + "not-an-ip       00:50:79:66:68:05  active\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+
+	_, err = parseLeases(header + "10.0.0.1        00:50:79:66:68:05  active   yesterday\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+}
+
+func TestUnit_DHCP_Leases(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_show(t, map[string]string{
+		"dhcp server leases":          leasesOutput14,
+		"dhcp server leases pool LAN": leasesOutputLegacy,
+	}))
+
+	leases, err := client.DHCP.Leases(ctx, "")
+	assert.NoError(t, err)
+	assert.Len(t, leases, 3)
+
+	leases, err = client.DHCP.Leases(ctx, "LAN")
+	assert.NoError(t, err)
+	assert.Len(t, leases, 2)
+
+	_, err = client.DHCP.Leases(ctx, "missing")
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+
+	resp, err := client.Show.Parse(ctx, []string{"dhcp", "server", "leases"})
+	assert.NoError(t, err)
+	assert.Len(t, resp, 3)
+}
+
+func TestUnit_DHCP_ClearLease(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_api(t, func(endpoint string, data any) (int, string) {
+		assert.Equal(t, "reset", endpoint)
+		assert.Equal(t, map[string]any{
+			"op":   "reset",
+			"path": []any{"dhcp", "server", "lease", "192.0.2.101"},
+		}, data)
+		return http.StatusOK, `{"success": true, "data": "", "error": null}`
+	}))
+
+	err := client.DHCP.ClearLease(ctx, netip.MustParseAddr("192.0.2.101"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// The requested configuration path does not exist or has no children.
+	ErrPathEmpty = errors.New("configuration under specified path is empty")
+	// The API key was missing or rejected by the server.
+	ErrUnauthorized = errors.New("unauthorized")
+	// The configuration session failed to commit.
+	ErrCommitFailed = errors.New("commit failed")
+	// The server answered with data in a shape the client did not expect.
+	ErrUnexpectedResponse = errors.New("received unexpected response format from server")
+)
+
+// An error returned by the VyOS API, either as a non-successful HTTP status
+// or as an `error` field in an otherwise successful response.
+type APIError struct {
+	// HTTP status code of the response
+	StatusCode int
+	// Endpoint the request was posted to, e.g. `retrieve`
+	Endpoint string
+	// Full URL the request was posted to
+	URL string
+	// Raw response body
+	Body []byte
+	// Error message reported by VyOS, if any
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode < 200 || e.StatusCode >= 300 {
+		return fmt.Sprintf(
+			"received non-successful (%d) response from vyos api (%s).\n%s",
+			e.StatusCode,
+			e.URL,
+			e.Body,
+		)
+	}
+	return e.Message
+}
+
+// Match the sentinel errors in this package so callers can use `errors.Is`.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrPathEmpty:
+		return strings.Contains(e.Message, "specified path is empty")
+	case ErrCommitFailed:
+		return strings.Contains(strings.ToLower(e.Message), "commit failed")
+	}
+	return false
+}
+
+func unexpectedResponse(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrUnexpectedResponse, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Errors_NonSuccess(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_response(http.StatusNotFound, `{"detail": "Not Found"}`))
+
+	_, err := client.Request(ctx, "foo", map[string]any{"op": "foo"})
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr), "expected an *APIError")
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "foo", apiErr.Endpoint)
+	assert.Equal(t, `{"detail": "Not Found"}`, string(apiErr.Body))
+	assert.ErrorContains(t, err, "received non-successful (404) response from vyos api ("+client.url+"/foo)")
+}
+
+func TestUnit_Errors_Unauthorized(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_response(
+		http.StatusUnauthorized,
+		`{"success": false, "error": "Valid API key is required", "data": null}`,
+	))
+
+	_, err := client.Request(ctx, "retrieve", map[string]any{"op": "showConfig", "path": []string{}})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.NotErrorIs(t, err, ErrPathEmpty)
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr), "expected an *APIError")
+	assert.Equal(t, "Valid API key is required", apiErr.Message)
+}
+
+func TestUnit_Errors_PathEmpty(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_response(
+		http.StatusBadRequest,
+		`{"success": false, "error": "Configuration under specified path is empty\n", "data": null}`,
+	))
+
+	_, err := client.Request(ctx, "retrieve", map[string]any{"op": "showConfig", "path": []string{"foo"}})
+	assert.ErrorIs(t, err, ErrPathEmpty)
+
+	// Show should consume the error
+	resp, err := client.Config.Show(ctx, "foo")
+	assert.NoError(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestUnit_Errors_CommitFailed(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_response(
+		http.StatusOK,
+		`{"success": false, "error": "[[system host-name]] failed\nCommit failed\n", "data": null}`,
+	))
+
+	err := client.Config.Set(ctx, "system host-name", "vyos")
+	assert.ErrorIs(t, err, ErrCommitFailed)
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr), "expected an *APIError")
+	assert.Equal(t, "[[system host-name]] failed\nCommit failed\n", apiErr.Message)
+}
+
+func TestUnit_Errors_UnexpectedResponse(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_response(http.StatusOK, `{"success": true, "data": "vyos", "error": null}`))
+
+	_, err := client.Config.Show(ctx, "system host-name")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+
+	_, err = client.ContainerImages.Show(ctx)
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+}
@@ -0,0 +1,241 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The endpoint of the GraphQL API, available since VyOS 1.4
+const graphQLEndpoint = "graphql"
+
+// Issues requests to the GraphQL API at `/graphql`.
+//
+// Requests go through the same middleware, hooks, retries and write ordering
+// as the rest of the client. Mutations are treated as writes, and queries as
+// reads which are safe to retry.
+//
+// VyOS has no generic mutation for changing the configuration, only ones
+// generated for specific templates, so use ConfigService for that, or Query
+// with a mutation from your release's schema.
+type GraphQLService struct{ client *Client }
+
+// The body of a GraphQL request
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+// An error reported by the GraphQL API
+type GraphQLError struct {
+	Message   string `json:"message"`
+	Path      []any  `json:"path,omitempty"`
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations,omitempty"`
+}
+
+func (e GraphQLError) Error() string {
+	return e.Message
+}
+
+// All errors reported by the GraphQL API for a request
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// Issue the GraphQL `query` with `variables`, and decode the `data` field
+// of the response into `out` as JSON. `out` may be nil to discard it.
+//
+// Errors in the response are returned as GraphQLErrors. Unlike the other
+// endpoints, the API key is not added automatically, and must be passed in
+// the query's input.
+func (svc *GraphQLService) Query(ctx context.Context, query string, variables map[string]any, out any) error {
+	resp, err := svc.client.Request(ctx, graphQLEndpoint, graphQLRequest{query, variables})
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// Return the configuration under `path`, or nil if it is empty
+func (svc *GraphQLService) ShowConfig(ctx context.Context, path Path) (any, error) {
+	var result any
+	err := svc.call(ctx, "query", "ShowConfig", map[string]any{"path": orEmpty(path)}, &result)
+	if err != nil {
+		if errors.Is(err, ErrPathEmpty) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// Decode the configuration under `path` into `v`. See Unmarshal.
+func (svc *GraphQLService) ShowConfigInto(ctx context.Context, path Path, v any) error {
+	tree, err := svc.ShowConfig(ctx, path)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(tree, v)
+}
+
+// Return the output of the operational mode command `show <path>`
+func (svc *GraphQLService) Show(ctx context.Context, path Path) (string, error) {
+	var result string
+	err := svc.call(ctx, "query", "Show", map[string]any{"path": orEmpty(path)}, &result)
+	return result, err
+}
+
+// Return the system status. Its fields vary between VyOS releases.
+func (svc *GraphQLService) SystemStatus(ctx context.Context) (map[string]any, error) {
+	var result map[string]any
+	err := svc.call(ctx, "query", "SystemStatus", map[string]any{}, &result)
+	return result, err
+}
+
+// Save the running configuration to `file`, or the boot configuration if empty
+func (svc *GraphQLService) SaveConfigFile(ctx context.Context, file string) error {
+	input := map[string]any{}
+	if file != "" {
+		input["fileName"] = file
+	}
+	return svc.call(ctx, "mutation", "SaveConfigFile", input, nil)
+}
+
+// Load the configuration from `file`, replacing the running configuration
+func (svc *GraphQLService) LoadConfigFile(ctx context.Context, file string) error {
+	return svc.call(ctx, "mutation", "LoadConfigFile", map[string]any{"fileName": file}, nil)
+}
+
+// The result of a VyOS operation in the GraphQL API
+type graphQLResult struct {
+	Success bool     `json:"success"`
+	Errors  []string `json:"errors"`
+	Data    *struct {
+		Result json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// Issue the VyOS operation `name` with `input` plus the API key, and decode
+// its result into `out` unless nil. `kind` is `query` or `mutation`.
+//
+// Failed mutations are returned as a *ConfigureError, like failed writes to
+// `configure`.
+func (svc *GraphQLService) call(ctx context.Context, kind string, name string, input map[string]any, out any) error {
+	input["key"] = svc.client.key
+	query := fmt.Sprintf("%s ($data: %sInput!) { %s(data: $data) { success errors data { result } } }",
+		kind, name, name)
+
+	var resp map[string]graphQLResult
+	err := svc.Query(ctx, query, map[string]any{"data": input}, &resp)
+	if err != nil {
+		return err
+	}
+
+	result, ok := resp[name]
+	if !ok {
+		return unexpectedResponse("graphql response is missing %s", name)
+	}
+	if !result.Success {
+		err := &APIError{
+			StatusCode: 200,
+			Endpoint:   graphQLEndpoint,
+			URL:        svc.client.url + "/" + graphQLEndpoint,
+			Message:    strings.Join(result.Errors, "\n"),
+		}
+		if kind == "mutation" {
+			return newConfigureError(err, nil)
+		}
+		return err
+	}
+
+	if out == nil || result.Data == nil || len(result.Data.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(result.Data.Result, out); err != nil {
+		return unexpectedResponse("%s", err.Error())
+	}
+	return nil
+}
+
+// Replace a nil path with an empty one, which the API requires
+func orEmpty(path Path) Path {
+	if path == nil {
+		return Path{}
+	}
+	return path
+}
+
+// Check whether a GraphQL request is a mutation
+func isMutation(query string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), "mutation")
+}
+
+// The body of a GraphQL response
+type graphQLResponse struct {
+	Data   any           `json:"data"`
+	Errors GraphQLErrors `json:"errors"`
+}
+
+// Post a single attempt of a GraphQL request with the marshaled body `data`.
+// See Client.post.
+func (c *Client) postGraphQL(ctx context.Context, endpoint string, data []byte) (any, int, error) {
+	var req graphQLRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, 0, fmt.Errorf("invalid graphql request: %w", err)
+	}
+
+	url := c.url + "/" + endpoint
+	release, err := c.scheduler.acquire(ctx, isMutation(req.Query))
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := c.resty.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(data).
+		Post(url)
+	release()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	statusCode := resp.StatusCode()
+	body := resp.Body()
+	r := new(graphQLResponse)
+	if statusCode < 200 || statusCode >= 300 {
+		apiErr := &APIError{statusCode, endpoint, url, body, ""}
+		if json.Unmarshal(body, &r) == nil && len(r.Errors) > 0 {
+			apiErr.Message = r.Errors.Error()
+		}
+		return nil, statusCode, apiErr
+	}
+
+	err = json.Unmarshal(body, &r)
+	if err != nil {
+		return nil, statusCode, unexpectedResponse("%s", err.Error())
+	}
+	if len(r.Errors) > 0 {
+		return nil, statusCode, r.Errors
+	}
+
+	return r.Data, statusCode, nil
+}
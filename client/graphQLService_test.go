@@ -0,0 +1,181 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A stub GraphQL API answering with the result of `handler` for the
+// request's query and variables
+func stub_graphql(t *testing.T, handler func(query string, variables map[string]any) (int, string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/graphql", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var req graphQLRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		assert.NoError(t, err)
+
+		status, body := handler(req.Query, req.Variables)
+		stub_response(status, body)(w, r)
+	}
+}
+
+// A stub answering a single VyOS operation with `result`, checking its input
+func stub_graphql_op(t *testing.T, name string, input map[string]any, result string) http.HandlerFunc {
+	return stub_graphql(t, func(query string, variables map[string]any) (int, string) {
+		assert.Contains(t, query, name+"(data: $data)")
+		assert.Equal(t, map[string]any{"data": input}, variables)
+		return http.StatusOK, `{"data": {"` + name + `": ` + result + `}}`
+	})
+}
+
+func TestUnit_GraphQL_Query(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_graphql(t, func(query string, variables map[string]any) (int, string) {
+		assert.Equal(t, "query { hello(name: $name) }", query)
+		assert.Equal(t, map[string]any{"name": "vyos"}, variables)
+		return http.StatusOK, `{"data": {"hello": "world"}}`
+	}))
+
+	var out struct{ Hello string }
+	err := client.GraphQL.Query(ctx, "query { hello(name: $name) }", map[string]any{"name": "vyos"}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "world", out.Hello)
+}
+
+func TestUnit_GraphQL_Errors(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_graphql(t, func(query string, variables map[string]any) (int, string) {
+		return http.StatusOK, `{"data": null, "errors": [
+			{"message": "Cannot query field 'foo'", "locations": [{"line": 1, "column": 9}]},
+			{"message": "Unknown argument 'bar'"}
+		]}`
+	}))
+
+	err := client.GraphQL.Query(ctx, "query { foo }", nil, nil)
+	var errs GraphQLErrors
+	assert.True(t, errors.As(err, &errs), "expected GraphQLErrors")
+	assert.Len(t, errs, 2)
+	assert.Equal(t, 9, errs[0].Locations[0].Column)
+	assert.EqualError(t, err, "graphql: Cannot query field 'foo'; Unknown argument 'bar'")
+}
+
+func TestUnit_GraphQL_HTTPError(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_graphql(t, func(query string, variables map[string]any) (int, string) {
+		return http.StatusBadRequest, `{"errors": [{"message": "Syntax Error"}]}`
+	}))
+
+	err := client.GraphQL.Query(ctx, "query {", nil, nil)
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr), "expected an *APIError")
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "graphql: Syntax Error", apiErr.Message)
+}
+
+func TestUnit_GraphQL_ShowConfig(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_graphql_op(t, "ShowConfig",
+		map[string]any{"key": "vyos", "path": []any{"system"}},
+		`{"success": true, "errors": [], "data": {"result": {"host-name": "vyos", "name-server": ["1.1.1.1", "1.0.0.1"]}}}`))
+
+	resp, err := client.GraphQL.ShowConfig(ctx, NewPath("system"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"host-name":   "vyos",
+		"name-server": []any{"1.1.1.1", "1.0.0.1"},
+	}, resp)
+
+	var system struct {
+		HostName    string   `vyos:"host-name"`
+		NameServers []string `vyos:"name-server"`
+	}
+	err = client.GraphQL.ShowConfigInto(ctx, NewPath("system"), &system)
+	assert.NoError(t, err)
+	assert.Equal(t, "vyos", system.HostName)
+	assert.Equal(t, []string{"1.1.1.1", "1.0.0.1"}, system.NameServers)
+}
+
+func TestUnit_GraphQL_ShowConfigEmpty(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_graphql_op(t, "ShowConfig",
+		map[string]any{"key": "vyos", "path": []any{"system", "domain-name"}},
+		`{"success": false, "errors": ["Configuration under specified path is empty\n"], "data": null}`))
+
+	resp, err := client.GraphQL.ShowConfig(ctx, NewPath("system", "domain-name"))
+	assert.NoError(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestUnit_GraphQL_Show(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_graphql_op(t, "Show",
+		map[string]any{"key": "vyos", "path": []any{"version"}},
+		`{"success": true, "errors": [], "data": {"result": "Version:          VyOS 1.4.0\n"}}`))
+
+	resp, err := client.GraphQL.Show(ctx, NewPath("version"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Version:          VyOS 1.4.0\n", resp)
+}
+
+func TestUnit_GraphQL_SystemStatus(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_graphql_op(t, "SystemStatus",
+		map[string]any{"key": "vyos"},
+		`{"success": true, "errors": [], "data": {"result": {"host_name": "vyos", "version": "1.4.0"}}}`))
+
+	resp, err := client.GraphQL.SystemStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"host_name": "vyos", "version": "1.4.0"}, resp)
+}
+
+func TestUnit_GraphQL_ConfigFile(t *testing.T) {
+	calls := []string{}
+	client, ctx := make_stub_client(t, stub_graphql(t, func(query string, variables map[string]any) (int, string) {
+		assert.True(t, strings.HasPrefix(query, "mutation"), "expected a mutation")
+		data := variables["data"].(map[string]any)
+		assert.Equal(t, "vyos", data["key"])
+
+		for _, name := range []string{"SaveConfigFile", "LoadConfigFile"} {
+			if strings.Contains(query, name+"(") {
+				calls = append(calls, name+" "+data["fileName"].(string))
+				return http.StatusOK, `{"data": {"` + name + `": {"success": true, "errors": [], "data": {"result": null}}}}`
+			}
+		}
+		return http.StatusOK, `{"data": null, "errors": [{"message": "unexpected query"}]}`
+	}))
+
+	assert.NoError(t, client.GraphQL.SaveConfigFile(ctx, "/config/test.boot"))
+	assert.NoError(t, client.GraphQL.LoadConfigFile(ctx, "/config/test.boot"))
+	assert.Equal(t, []string{"SaveConfigFile /config/test.boot", "LoadConfigFile /config/test.boot"}, calls)
+}
+
+func TestUnit_GraphQL_OperationFailed(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_graphql_op(t, "LoadConfigFile",
+		map[string]any{"key": "vyos", "fileName": "/config/missing.boot"},
+		`{"success": false, "errors": ["Cannot open configuration file"], "data": null}`))
+
+	err := client.GraphQL.LoadConfigFile(ctx, "/config/missing.boot")
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr), "expected an *APIError")
+	assert.Equal(t, "Cannot open configuration file", apiErr.Message)
+}
+
+func TestUnit_GraphQL_MutationFailed(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_graphql_op(t, "LoadConfigFile",
+		map[string]any{"key": "vyos", "fileName": "/config/test.boot"},
+		`{"success": false, "errors": ["[ interfaces ethernet eth0 ]\nMTU must be at least 1280\n\n[[interfaces ethernet eth0]] failed\nCommit failed"], "data": null}`))
+
+	// Mutation failures are wrapped like `configure` failures
+	err := client.GraphQL.LoadConfigFile(ctx, "/config/test.boot")
+	var configureErr *ConfigureError
+	assert.True(t, errors.As(err, &configureErr), "expected a *ConfigureError")
+	assert.Empty(t, configureErr.Operations)
+	assert.Empty(t, configureErr.Failed)
+	assert.ErrorIs(t, err, ErrCommitFailed)
+	assert.Contains(t, configureErr.Err.Message, "MTU must be at least 1280")
+}
+
+func TestUnit_GraphQL_Idempotent(t *testing.T) {
+	assert.True(t, IsIdempotent("graphql", graphQLRequest{Query: "query { foo }"}))
+	assert.False(t, IsIdempotent("graphql", graphQLRequest{Query: " mutation { foo }"}))
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Replaces the API key wherever it appears in a payload given to hooks
+const redacted = "[REDACTED]"
+
+// A request about to be sent to the API
+type RequestEvent struct {
+	Endpoint string
+	// The decoded `data` field, with the API key redacted
+	Payload any
+	// Starts at 1, and increases when the request is retried
+	Attempt int
+}
+
+// The outcome of a request sent to the API
+type ResponseEvent struct {
+	RequestEvent
+	Duration time.Duration
+	// HTTP status of the response, or 0 if none was received
+	StatusCode int
+	// The `data` field of a successful response
+	Data any
+	// Non-nil if the request failed. The error reported by VyOS, if any,
+	// is available through errors.As with *APIError.
+	Err error
+}
+
+// Called before each request attempt
+type BeforeRequestHook interface {
+	BeforeRequest(ctx context.Context, event *RequestEvent)
+}
+
+// Called after each request attempt, whether or not it succeeded
+type AfterResponseHook interface {
+	AfterResponse(ctx context.Context, event *ResponseEvent)
+}
+
+// Adapts a function to a BeforeRequestHook
+type BeforeRequestFunc func(ctx context.Context, event *RequestEvent)
+
+func (f BeforeRequestFunc) BeforeRequest(ctx context.Context, event *RequestEvent) {
+	f(ctx, event)
+}
+
+// Adapts a function to an AfterResponseHook
+type AfterResponseFunc func(ctx context.Context, event *ResponseEvent)
+
+func (f AfterResponseFunc) AfterResponse(ctx context.Context, event *ResponseEvent) {
+	f(ctx, event)
+}
+
+// Call `hook` before each request attempt. See Client.AddBeforeRequestHook.
+func WithBeforeRequest(hook BeforeRequestHook) Option {
+	return func(o *options) { o.beforeRequest = append(o.beforeRequest, hook) }
+}
+
+// Call `hook` after each request attempt. See Client.AddAfterResponseHook.
+func WithAfterResponse(hook AfterResponseHook) Option {
+	return func(o *options) { o.afterResponse = append(o.afterResponse, hook) }
+}
+
+// Call `hook` before each request attempt, after any hooks added before it.
+//
+// Hooks run synchronously on the goroutine making the request, and must not
+// modify the event. Hooks must be added before the client is first used.
+func (c *Client) AddBeforeRequestHook(hook BeforeRequestHook) {
+	c.beforeRequest = append(c.beforeRequest, hook)
+}
+
+// Call `hook` after each request attempt, after any hooks added before it.
+//
+// Hooks run synchronously on the goroutine making the request, and must not
+// modify the event. Hooks must be added before the client is first used.
+func (c *Client) AddAfterResponseHook(hook AfterResponseHook) {
+	c.afterResponse = append(c.afterResponse, hook)
+}
+
+// Check whether anything observes requests, so that building events can be
+// skipped otherwise
+func (c *Client) observed() bool {
+	return c.logger != nil || len(c.beforeRequest) > 0 || len(c.afterResponse) > 0
+}
+
+// Decode the marshaled payload `data` for hooks, redacting the API key
+func (c *Client) redactPayload(data []byte) any {
+	var payload any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil
+	}
+	return redact(payload, c.key)
+}
+
+// Replace every string equal to `secret` in a decoded JSON value
+func redact(v any, secret string) any {
+	switch v := v.(type) {
+	case string:
+		if secret != "" && v == secret {
+			return redacted
+		}
+	case []any:
+		for i := range v {
+			v[i] = redact(v[i], secret)
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = redact(v[k], secret)
+		}
+	}
+	return v
+}
+
+func (c *Client) beforeRequestHooks(ctx context.Context, event *RequestEvent) {
+	for _, hook := range c.beforeRequest {
+		hook.BeforeRequest(ctx, event)
+	}
+}
+
+func (c *Client) afterResponseHooks(ctx context.Context, event *ResponseEvent) {
+	c.log(ctx, event)
+	for _, hook := range c.afterResponse {
+		hook.AfterResponse(ctx, event)
+	}
+}
+
+// Log the outcome of a single request attempt, if a logger is configured
+func (c *Client) log(ctx context.Context, event *ResponseEvent) {
+	if c.logger == nil {
+		return
+	}
+
+	args := []any{
+		"endpoint", event.Endpoint,
+		"payload", event.Payload,
+		"attempt", event.Attempt,
+		"duration", event.Duration,
+		"status", event.StatusCode,
+	}
+	if event.Err != nil {
+		c.logger.WarnContext(ctx, "vyos api request failed", append(args, "error", event.Err)...)
+		return
+	}
+	c.logger.DebugContext(ctx, "vyos api request", args...)
+}
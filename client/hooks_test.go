@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Hooks_Events(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_response(http.StatusBadRequest,
+		`{"success": false, "data": null, "error": "Set failed"}`))
+
+	var before []RequestEvent
+	var after []ResponseEvent
+	client.AddBeforeRequestHook(BeforeRequestFunc(func(ctx context.Context, event *RequestEvent) {
+		before = append(before, *event)
+	}))
+	client.AddAfterResponseHook(AfterResponseFunc(func(ctx context.Context, event *ResponseEvent) {
+		after = append(after, *event)
+	}))
+
+	err := client.Config.Set(ctx, "system host-name", "router")
+	assert.Error(t, err)
+
+	payload := []any{map[string]any{
+		"op":    "set",
+		"path":  []any{"system", "host-name"},
+		"value": "router",
+	}}
+	assert.Equal(t, []RequestEvent{{"configure", payload, 1}}, before)
+
+	assert.Len(t, after, 1)
+	assert.Equal(t, before[0], after[0].RequestEvent)
+	assert.Equal(t, http.StatusBadRequest, after[0].StatusCode)
+	assert.Nil(t, after[0].Data)
+	var apiErr *APIError
+	assert.True(t, errors.As(after[0].Err, &apiErr), "expected an *APIError")
+	assert.Equal(t, "Set failed", apiErr.Message)
+}
+
+func TestUnit_Hooks_Success(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_response(http.StatusOK,
+		`{"success": true, "data": "vyos", "error": null}`))
+
+	var after []ResponseEvent
+	client.AddAfterResponseHook(AfterResponseFunc(func(ctx context.Context, event *ResponseEvent) {
+		after = append(after, *event)
+	}))
+
+	_, err := client.Config.ReturnValue(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Len(t, after, 1)
+	assert.Equal(t, "retrieve", after[0].Endpoint)
+	assert.Equal(t, http.StatusOK, after[0].StatusCode)
+	assert.Equal(t, "vyos", after[0].Data)
+	assert.NoError(t, after[0].Err)
+}
+
+func TestUnit_Hooks_RedactKey(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_response(http.StatusOK,
+		`{"success": true, "data": null, "error": null}`))
+
+	var payload any
+	client.AddBeforeRequestHook(BeforeRequestFunc(func(ctx context.Context, event *RequestEvent) {
+		payload = event.Payload
+	}))
+
+	err := client.Config.Set(ctx, "service https api keys id apikey key", "vyos")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{
+		"op":    "set",
+		"path":  []any{"service", "https", "api", "keys", "id", "apikey", "key"},
+		"value": "[REDACTED]",
+	}}, payload)
+}
+
+func TestUnit_Hooks_Options(t *testing.T) {
+	attempts := 0
+	server := stub_flaky(t, 1, http.StatusServiceUnavailable, &attempts)
+	stub, ctx := make_stub_client(t, server)
+
+	var statuses []int
+	client := NewWithClient(stub.resty.GetClient(), stub.url, "vyos",
+		WithRetryPolicy(fastRetryPolicy()),
+		WithAfterResponse(AfterResponseFunc(func(ctx context.Context, event *ResponseEvent) {
+			statuses = append(statuses, event.StatusCode)
+		})),
+	)
+
+	_, err := client.Config.Show(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{http.StatusServiceUnavailable, http.StatusOK}, statuses)
+}
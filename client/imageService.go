@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// Installs and removes the system images the router boots from
+type ImageService struct{ client *Client }
+
+// A row of `show system image`
+type SystemImage struct {
+	Name string
+	// The image booted by default
+	Default bool
+	// The image currently running
+	Running bool
+}
+
+// Download and install the image at `url`.
+//
+// This can take many minutes, so it is bounded by the timeout set by
+// WithImageTimeout rather than WithTimeout.
+func (svc *ImageService) Add(ctx context.Context, url string) error {
+	_, err := svc.client.Request(ctx, "image", map[string]any{
+		"op":  "add",
+		"url": url,
+	})
+	return err
+}
+
+// Delete the image `name`
+func (svc *ImageService) Delete(ctx context.Context, name string) error {
+	_, err := svc.client.Request(ctx, "image", map[string]any{
+		"op":   "delete",
+		"name": name,
+	})
+	return err
+}
+
+// Boot the image `name` by default
+func (svc *ImageService) SetDefault(ctx context.Context, name string) error {
+	_, err := svc.client.Request(ctx, "image", map[string]any{
+		"op":   "set_default",
+		"name": name,
+	})
+	return err
+}
+
+// Return the installed images
+func (svc *ImageService) List(ctx context.Context) ([]SystemImage, error) {
+	output, err := svc.client.Show.Run(ctx, []string{"system", "image"})
+	if err != nil {
+		return nil, err
+	}
+	return parseSystemImages(output)
+}
+
+// VyOS 1.4 and later
+var systemImageHeaderPattern = regexp.MustCompile(`^Name\s+Default boot\s+Running`)
+
+// VyOS 1.3, e.g. `1: 1.3.4 (default boot) (running image)`
+var systemImageLegacyHeaderPattern = regexp.MustCompile(`following image\(s\) installed:$`)
+var systemImageLegacyLinePattern = regexp.MustCompile(`^\d+: (?P<name>\S+)(?P<flags>(?: \([^)]+\))*)$`)
+
+func parseSystemImages(data string) ([]SystemImage, error) {
+	images := []SystemImage{}
+
+	lines := strings.Split(data, "\n")
+	var layout *tableLayout
+	legacy := false
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if layout == nil && !legacy {
+			if systemImageHeaderPattern.MatchString(line) {
+				rule := ""
+				if i+1 < len(lines) && isTableRule(lines[i+1]) {
+					rule = lines[i+1]
+				}
+				l := newTableLayout(line, rule)
+				layout = &l
+			}
+			legacy = systemImageLegacyHeaderPattern.MatchString(line)
+			continue
+		}
+
+		if legacy {
+			match, ok := matchStringNamed(systemImageLegacyLinePattern, strings.TrimSpace(line))
+			if !ok {
+				return nil, unexpectedResponse("invalid system image in response from vyos api:\n%s", line)
+			}
+			images = append(images, SystemImage{
+				Name:    match["name"],
+				Default: strings.Contains(match["flags"], "(default boot)"),
+				Running: strings.Contains(match["flags"], "(running image)"),
+			})
+			continue
+		}
+
+		if isTableRule(line) {
+			continue
+		}
+		cells := layout.cells(line)
+		if cells["Name"] == "" {
+			return nil, unexpectedResponse("invalid system image in response from vyos api:\n%s", line)
+		}
+		images = append(images, SystemImage{
+			Name:    cells["Name"],
+			Default: strings.EqualFold(cells["Default boot"], "yes"),
+			Running: strings.EqualFold(cells["Running"], "yes"),
+		})
+	}
+
+	if layout == nil && !legacy {
+		return nil, unexpectedResponse("could not find expected system image header in response from vyos api:\n%s", data)
+	}
+	return images, nil
+}
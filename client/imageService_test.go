@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// VyOS 1.2 and 1.3
+const systemImageOutputLegacy = `The system currently has the following image(s) installed:
+
+   1: 1.3.4 (default boot) (running image)
+   2: 1.3.3
+   3: 1.2.9-S1 (running image)
+
+`
+
+// VyOS 1.4 and later
+const systemImageOutput = `Name                              Default boot    Running
+--------------------------------  --------------  ---------
+1.4.0                             Yes             Yes
+1.5-rolling-202402120023
+1.3.6                                             Yes
+`
+
+func TestUnit_SystemImages_ParseLegacy(t *testing.T) {
+	images, err := parseSystemImages(systemImageOutputLegacy)
+	assert.NoError(t, err)
+	assert.Equal(t, []SystemImage{
+		{Name: "1.3.4", Default: true, Running: true},
+		{Name: "1.3.3"},
+		{Name: "1.2.9-S1", Running: true},
+	}, images)
+}
+
+func TestUnit_SystemImages_Parse(t *testing.T) {
+	images, err := parseSystemImages(systemImageOutput)
+	assert.NoError(t, err)
+	assert.Equal(t, []SystemImage{
+		{Name: "1.4.0", Default: true, Running: true},
+		{Name: "1.5-rolling-202402120023"},
+		{Name: "1.3.6", Running: true},
+	}, images)
+}
+
+func TestUnit_SystemImages_ParseInvalid(t *testing.T) {
+	_, err := parseSystemImages("Invalid command\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+
+	_, err = parseSystemImages("The system currently has the following image(s) installed:\n\nfoo\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+
+	images, err := parseSystemImages("Name   Default boot    Running\n----   ------------    -------\n")
+	assert.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestUnit_Images_Requests(t *testing.T) {
+	requests := []any{}
+	client, ctx := make_stub_client(t, stub_api(t, func(endpoint string, data any) (int, string) {
+		requests = append(requests, []any{endpoint, data})
+		if endpoint == "show" {
+			body, _ := json.Marshal(map[string]any{"success": true, "data": systemImageOutput, "error": nil})
+			return http.StatusOK, string(body)
+		}
+		return http.StatusOK, `{"success": true, "data": "", "error": null}`
+	}))
+
+	assert.NoError(t, client.Images.Add(ctx, "https://example.com/vyos-1.4.1-amd64.iso"))
+	assert.NoError(t, client.Images.SetDefault(ctx, "1.4.1"))
+	assert.NoError(t, client.Images.Delete(ctx, "1.3.6"))
+
+	images, err := client.Images.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, images, 3)
+
+	assert.Equal(t, []any{
+		[]any{"image", map[string]any{"op": "add", "url": "https://example.com/vyos-1.4.1-amd64.iso"}},
+		[]any{"image", map[string]any{"op": "set_default", "name": "1.4.1"}},
+		[]any{"image", map[string]any{"op": "delete", "name": "1.3.6"}},
+		[]any{"show", map[string]any{"op": "show", "path": []any{"system", "image"}}},
+	}, requests)
+
+	resp, err := client.Show.Parse(ctx, []string{"system", "image"})
+	assert.NoError(t, err)
+	assert.Equal(t, images, resp)
+}
+
+func TestUnit_Images_Timeout(t *testing.T) {
+	server := httptest.NewServer(stub_api(t, func(endpoint string, data any) (int, string) {
+		time.Sleep(100 * time.Millisecond)
+		return http.StatusOK, `{"success": true, "data": "", "error": null}`
+	}))
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+
+	// Installing an image outlives the client-wide timeout
+	client := NewWithClient(server.Client(), server.URL, "vyos", WithTimeout(20*time.Millisecond))
+	assert.NoError(t, client.Images.Add(ctx, "https://example.com/vyos-1.4.1-amd64.iso"))
+	_, err := client.Config.Show(ctx, "system host-name")
+	assert.Error(t, err)
+
+	// But is still bounded by its own
+	client = NewWithClient(server.Client(), server.URL, "vyos", WithImageTimeout(20*time.Millisecond))
+	assert.Error(t, client.Images.Add(ctx, "https://example.com/vyos-1.4.1-amd64.iso"))
+}
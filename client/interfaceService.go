@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reports the state of the router's interfaces
+type InterfaceService struct{ client *Client }
+
+// The state of an interface, or of its link, as shown by `show interfaces`
+type InterfaceState string
+
+const (
+	InterfaceUp        InterfaceState = "u"
+	InterfaceDown      InterfaceState = "D"
+	InterfaceAdminDown InterfaceState = "A"
+)
+
+// A row of `show interfaces`
+type InterfaceStatus struct {
+	Name string
+	// Addresses with their prefix length, e.g. 192.0.2.1/24
+	Addresses []string
+	// Only shown by VyOS 1.5 and later, empty otherwise
+	MAC string
+	VRF string
+	MTU int
+	// Administrative state, up or admin down
+	State InterfaceState
+	// Link state, up or down
+	Link        InterfaceState
+	Description string
+}
+
+// Check whether the interface is administratively up and has a link
+func (s InterfaceStatus) Up() bool {
+	return s.State == InterfaceUp && s.Link == InterfaceUp
+}
+
+// Return the name, addresses, state and description of every interface
+func (svc *InterfaceService) Status(ctx context.Context) ([]InterfaceStatus, error) {
+	output, err := svc.client.Show.Run(ctx, []string{"interfaces"})
+	if err != nil {
+		return nil, err
+	}
+	return parseInterfaces(output)
+}
+
+var interfaceHeaderPattern = regexp.MustCompile(`^Interface\s+IP Address\s+.*S/L`)
+var interfaceStatePattern = regexp.MustCompile(`^(?P<state>[uDA])/(?P<link>[uD])$`)
+
+func parseInterfaces(data string) ([]InterfaceStatus, error) {
+	interfaces := []InterfaceStatus{}
+
+	lines := strings.Split(data, "\n")
+	var layout *tableLayout
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if layout == nil {
+			if interfaceHeaderPattern.MatchString(line) {
+				rule := ""
+				if i+1 < len(lines) && isTableRule(lines[i+1]) {
+					rule = lines[i+1]
+				}
+				l := newTableLayout(line, rule)
+				layout = &l
+			}
+			continue
+		}
+		if isTableRule(line) {
+			continue
+		}
+
+		cells := layout.cells(line)
+
+		// Further addresses of the previous interface are on their own lines
+		if cells["Interface"] == "" {
+			if len(interfaces) == 0 {
+				return nil, unexpectedResponse("invalid interface in response from vyos api:\n%s", line)
+			}
+			if address := cells["IP Address"]; address != "" && address != "-" {
+				last := &interfaces[len(interfaces)-1]
+				last.Addresses = append(last.Addresses, address)
+			}
+			continue
+		}
+
+		state, ok := matchStringNamed(interfaceStatePattern, cells["S/L"])
+		if !ok {
+			return nil, unexpectedResponse("invalid interface in response from vyos api:\n%s", line)
+		}
+
+		status := InterfaceStatus{
+			Name:        cells["Interface"],
+			Addresses:   []string{},
+			MAC:         cells["MAC"],
+			VRF:         cells["VRF"],
+			State:       InterfaceState(state["state"]),
+			Link:        InterfaceState(state["link"]),
+			Description: cells["Description"],
+		}
+		if address := cells["IP Address"]; address != "" && address != "-" {
+			status.Addresses = append(status.Addresses, address)
+		}
+		if mtu := cells["MTU"]; mtu != "" {
+			status.MTU, _ = strconv.Atoi(mtu)
+		}
+
+		interfaces = append(interfaces, status)
+	}
+
+	if layout == nil {
+		return nil, unexpectedResponse("could not find expected interface header in response from vyos api:\n%s", data)
+	}
+	return interfaces, nil
+}
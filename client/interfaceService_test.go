@@ -0,0 +1,135 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// VyOS 1.2 and 1.3
+const interfacesOutputLegacy = `Codes: S - State, L - Link, u - Up, D - Down, A - Admin Down
+Interface        IP Address                        S/L  Description
+---------        ----------                        ---  -----------
+eth0             192.0.2.1/24                      u/u  WAN uplink
+                 2001:db8::1/64
+eth1             -                                 A/D
+eth2             10.0.0.1/24                       u/D  LAN
+eth2.100         10.100.0.1/24                     u/u
+lo               127.0.0.1/8                       u/u
+                 ::1/128
+`
+
+// VyOS 1.4, where long names push the other columns along
+const interfacesOutput14 = `Codes: S - State, L - Link, u - Up, D - Down, A - Admin Down
+Interface        IP Address                        S/L  Description
+---------        ----------                        ---  -----------
+eth0             198.51.100.7/24                   u/u
+eth0.100.200.300 172.16.0.1/30                     u/u  QinQ to datacenter
+wg0              10.10.0.1/24                      u/u  VPN
+                 fd00:10::1/64
+lo               127.0.0.1/8                       u/u
+                 ::1/128
+`
+
+// VyOS 1.5, formatted with tabulate and showing more columns
+const interfacesOutput15 = `Codes: S - State, L - Link, u - Up, D - Down, A - Admin Down
+Interface    IP Address      MAC                VRF        MTU  S/L    Description
+-----------  --------------  -----------------  -------  -----  -----  ----------------
+eth0         192.0.2.1/24    00:50:56:aa:bb:cc  default   1500  u/u    WAN uplink
+             2001:db8::1/64
+eth1         -               00:50:56:aa:bb:cd  mgmt      9000  A/D
+lo           127.0.0.1/8     00:00:00:00:00:00  default  65536  u/u
+             ::1/128
+`
+
+func TestUnit_Interfaces_ParseLegacy(t *testing.T) {
+	interfaces, err := parseInterfaces(interfacesOutputLegacy)
+	assert.NoError(t, err)
+	assert.Equal(t, []InterfaceStatus{
+		{Name: "eth0", Addresses: []string{"192.0.2.1/24", "2001:db8::1/64"}, State: InterfaceUp, Link: InterfaceUp, Description: "WAN uplink"},
+		{Name: "eth1", Addresses: []string{}, State: InterfaceAdminDown, Link: InterfaceDown},
+		{Name: "eth2", Addresses: []string{"10.0.0.1/24"}, State: InterfaceUp, Link: InterfaceDown, Description: "LAN"},
+		{Name: "eth2.100", Addresses: []string{"10.100.0.1/24"}, State: InterfaceUp, Link: InterfaceUp},
+		{Name: "lo", Addresses: []string{"127.0.0.1/8", "::1/128"}, State: InterfaceUp, Link: InterfaceUp},
+	}, interfaces)
+
+	assert.True(t, interfaces[0].Up())
+	assert.False(t, interfaces[1].Up())
+	assert.False(t, interfaces[2].Up())
+}
+
+func TestUnit_Interfaces_Parse14(t *testing.T) {
+	interfaces, err := parseInterfaces(interfacesOutput14)
+	assert.NoError(t, err)
+	assert.Len(t, interfaces, 4)
+
+	assert.Equal(t, InterfaceStatus{
+		Name:        "eth0.100.200.300",
+		Addresses:   []string{"172.16.0.1/30"},
+		State:       InterfaceUp,
+		Link:        InterfaceUp,
+		Description: "QinQ to datacenter",
+	}, interfaces[1])
+	assert.Equal(t, []string{"10.10.0.1/24", "fd00:10::1/64"}, interfaces[2].Addresses)
+	assert.Equal(t, "VPN", interfaces[2].Description)
+}
+
+func TestUnit_Interfaces_Parse15(t *testing.T) {
+	interfaces, err := parseInterfaces(interfacesOutput15)
+	assert.NoError(t, err)
+	assert.Equal(t, []InterfaceStatus{
+		{
+			Name:        "eth0",
+			Addresses:   []string{"192.0.2.1/24", "2001:db8::1/64"},
+			MAC:         "00:50:56:aa:bb:cc",
+			VRF:         "default",
+			MTU:         1500,
+			State:       InterfaceUp,
+			Link:        InterfaceUp,
+			Description: "WAN uplink",
+		},
+		{
+			Name:      "eth1",
+			Addresses: []string{},
+			MAC:       "00:50:56:aa:bb:cd",
+			VRF:       "mgmt",
+			MTU:       9000,
+			State:     InterfaceAdminDown,
+			Link:      InterfaceDown,
+		},
+		{
+			Name:      "lo",
+			Addresses: []string{"127.0.0.1/8", "::1/128"},
+			MAC:       "00:00:00:00:00:00",
+			VRF:       "default",
+			MTU:       65536,
+			State:     InterfaceUp,
+			Link:      InterfaceUp,
+		},
+	}, interfaces)
+}
+
+func TestUnit_Interfaces_ParseInvalid(t *testing.T) {
+	_, err := parseInterfaces("Invalid command\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+
+	_, err = parseInterfaces("Interface        IP Address                        S/L  Description\n" +
+		"eth0             192.0.2.1/24                      up   WAN\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+
+	interfaces, err := parseInterfaces("Interface        IP Address                        S/L  Description\n")
+	assert.NoError(t, err)
+	assert.Empty(t, interfaces)
+}
+
+func TestUnit_Interfaces_Status(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_show(t, map[string]string{"interfaces": interfacesOutputLegacy}))
+
+	interfaces, err := client.Interfaces.Status(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, interfaces, 5)
+
+	resp, err := client.Show.Parse(ctx, []string{"interfaces"})
+	assert.NoError(t, err)
+	assert.Equal(t, interfaces, resp)
+}
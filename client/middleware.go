@@ -0,0 +1,34 @@
+package client
+
+import "context"
+
+// Sends a request to `endpoint` with `payload`, like Client.Request
+type RequestFunc func(ctx context.Context, endpoint string, payload any) (any, error)
+
+// Wraps the sending of requests, e.g. to trace them or collect metrics.
+//
+// Middleware sees each call to Client.Request once, around all of its retry
+// attempts. Use hooks to observe individual attempts.
+type Middleware func(next RequestFunc) RequestFunc
+
+// Wrap every request with `middleware`. See Client.Use.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(o *options) { o.middleware = append(o.middleware, middleware...) }
+}
+
+// Wrap every request with `middleware`. The first middleware given runs
+// outermost, and middleware added by later calls runs inside it.
+//
+// Middleware must be added before the client is first used.
+func (c *Client) Use(middleware ...Middleware) {
+	if len(middleware) == 0 {
+		return
+	}
+	c.middleware = append(c.middleware, middleware...)
+
+	handler := RequestFunc(c.request)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i](handler)
+	}
+	c.handler = handler
+}
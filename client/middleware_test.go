@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A middleware appending `name` to `calls` on the way in and out
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next RequestFunc) RequestFunc {
+		return func(ctx context.Context, endpoint string, payload any) (any, error) {
+			*calls = append(*calls, name+" "+endpoint)
+			resp, err := next(ctx, endpoint, payload)
+			*calls = append(*calls, name+" done")
+			return resp, err
+		}
+	}
+}
+
+func TestUnit_Middleware_Order(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_response(http.StatusOK,
+		`{"success": true, "data": "vyos", "error": null}`))
+
+	calls := []string{}
+	client.Use(recordingMiddleware("a", &calls), recordingMiddleware("b", &calls))
+	client.Use(recordingMiddleware("c", &calls))
+
+	resp, err := client.Config.ReturnValue(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "vyos", resp)
+	assert.Equal(t, []string{
+		"a retrieve", "b retrieve", "c retrieve",
+		"c done", "b done", "a done",
+	}, calls)
+}
+
+func TestUnit_Middleware_AroundRetries(t *testing.T) {
+	attempts := 0
+	client, ctx := make_stub_client(t, stub_flaky(t, 2, http.StatusServiceUnavailable, &attempts))
+	client.SetRetryPolicy(fastRetryPolicy())
+
+	calls := []string{}
+	client.Use(recordingMiddleware("a", &calls))
+
+	_, err := client.Config.Show(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []string{"a retrieve", "a done"}, calls)
+}
+
+func TestUnit_Middleware_ShortCircuit(t *testing.T) {
+	requests := 0
+	client := New("http://localhost:0", "vyos", WithMiddleware(func(next RequestFunc) RequestFunc {
+		return func(ctx context.Context, endpoint string, payload any) (any, error) {
+			requests++
+			return "cached", nil
+		}
+	}))
+
+	resp, err := client.Config.ReturnValue(context.Background(), "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "cached", resp)
+	assert.Equal(t, 1, requests)
+}
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Configures a Client created by New or NewWithClient
+type Option func(*options)
+
+type options struct {
+	httpClient   *http.Client
+	timeout      time.Duration
+	imageTimeout time.Duration
+	tlsConfig    *tls.Config
+	caFiles      []string
+	insecure     bool
+	certFile     string
+	keyFile      string
+	proxy        string
+
+	userAgent      string
+	headers        map[string]string
+	basePath       string
+	retry          *RetryPolicy
+	logger         Logger
+	beforeRequest  []BeforeRequestHook
+	afterResponse  []AfterResponseHook
+	middleware     []Middleware
+	writePolicy    *WritePolicy
+	maxConcurrency int
+}
+
+// A structured logger. *slog.Logger satisfies this interface.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...any)
+	InfoContext(ctx context.Context, msg string, args ...any)
+	WarnContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+// Use `c` to make requests instead of building an HTTP client.
+//
+// The options configuring the HTTP client itself (WithTimeout, WithTLSConfig,
+// WithCAFile, WithInsecureSkipVerify, WithClientCertificate and WithProxy)
+// are ignored when this option is given.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) { o.httpClient = c }
+}
+
+// Set the timeout of each request attempt. Defaults to 10 seconds.
+//
+// Requests to the `image` endpoint, which download and install system images,
+// use the timeout set by WithImageTimeout instead.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// Set the timeout of each attempt of a request to the `image` endpoint, such
+// as ImageService.Add. Defaults to 30 minutes. Zero means no timeout.
+//
+// Unlike WithTimeout, this also applies to a client given by WithHTTPClient.
+func WithImageTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.imageTimeout = timeout }
+}
+
+// Use `config` as the base TLS configuration. It is cloned before any other
+// TLS options are applied.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) { o.tlsConfig = config }
+}
+
+// Trust the PEM encoded CA certificates in `file`, in addition to the
+// system roots.
+func WithCAFile(file string) Option {
+	return func(o *options) { o.caFiles = append(o.caFiles, file) }
+}
+
+// Skip verification of the server's certificate. Only use this for testing.
+func WithInsecureSkipVerify() Option {
+	return func(o *options) { o.insecure = true }
+}
+
+// Authenticate with the PEM encoded client certificate and key in
+// `certFile` and `keyFile`.
+func WithClientCertificate(certFile string, keyFile string) Option {
+	return func(o *options) { o.certFile, o.keyFile = certFile, keyFile }
+}
+
+// Send requests through the proxy at `proxyURL`. By default the proxy is
+// taken from the environment.
+func WithProxy(proxyURL string) Option {
+	return func(o *options) { o.proxy = proxyURL }
+}
+
+// Set the User-Agent header sent with each request
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) { o.userAgent = userAgent }
+}
+
+// Add a header sent with each request
+func WithHeader(key string, value string) Option {
+	return func(o *options) {
+		if o.headers == nil {
+			o.headers = map[string]string{}
+		}
+		o.headers[key] = value
+	}
+}
+
+// Prefix every endpoint with `path`, for an API served below the root of
+// the URL, e.g. behind a reverse proxy.
+func WithBasePath(path string) Option {
+	return func(o *options) { o.basePath = path }
+}
+
+// Set the policy for retrying failed requests. See Client.SetRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) { o.retry = &policy }
+}
+
+// Log each request to `logger`
+func WithLogger(logger Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// Set how writes are ordered against other requests. See Client.SetWritePolicy.
+func WithWritePolicy(policy WritePolicy) Option {
+	return func(o *options) { o.writePolicy = &policy }
+}
+
+// Limit the number of requests in flight at once. See Client.SetMaxConcurrency.
+func WithMaxConcurrency(n int) Option {
+	return func(o *options) { o.maxConcurrency = n }
+}
+
+// Build the HTTP client described by the options
+func (o *options) buildHTTPClient() (*http.Client, error) {
+	if o.httpClient != nil {
+		return o.httpClient, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsConfig := &tls.Config{}
+	if o.tlsConfig != nil {
+		tlsConfig = o.tlsConfig.Clone()
+	}
+	if o.insecure {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	if len(o.caFiles) > 0 {
+		pool := tlsConfig.RootCAs
+		if pool == nil {
+			var err error
+			pool, err = x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+		}
+		for _, file := range o.caFiles {
+			pem, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA file %s", file)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if o.certFile != "" || o.keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.certFile, o.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if o.proxy != "" {
+		proxy, err := url.Parse(o.proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Timeout: o.timeout, Transport: transport}, nil
+}
+
+// Join a base URL and path without doubling or dropping slashes
+func joinURL(base string, path string) string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	return strings.TrimSuffix(base, "/") + "/" + path
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const okResponse = `{"success": true, "data": "vyos", "error": null}`
+
+func TestUnit_Options_HeadersAndBasePath(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		stub_response(http.StatusOK, okResponse)(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client := New(server.URL+"/", "vyos",
+		WithHTTPClient(server.Client()),
+		WithBasePath("/vyos/"),
+		WithUserAgent("vyos-client-test"),
+		WithHeader("X-Test", "yes"),
+	)
+	_, err := client.Request(context.Background(), "retrieve", map[string]any{"op": "exists"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/vyos/retrieve", got.URL.Path)
+	assert.Equal(t, "vyos-client-test", got.Header.Get("User-Agent"))
+	assert.Equal(t, "yes", got.Header.Get("X-Test"))
+}
+
+func TestUnit_Options_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(stub_response(http.StatusOK, okResponse))
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+
+	// Untrusted by default
+	_, err := New(server.URL, "vyos").Request(ctx, "retrieve", nil)
+	assert.Error(t, err)
+
+	_, err = New(server.URL, "vyos", WithInsecureSkipVerify()).Request(ctx, "retrieve", nil)
+	assert.NoError(t, err)
+
+	// Trusted through a CA file
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	assert.NoError(t, os.WriteFile(file, pem.EncodeToMemory(block), 0644))
+
+	_, err = New(server.URL, "vyos", WithCAFile(file)).Request(ctx, "retrieve", nil)
+	assert.NoError(t, err)
+}
+
+func TestUnit_Options_Invalid(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := New("https://localhost", "vyos", WithCAFile("missing.pem")).Request(ctx, "retrieve", nil)
+	assert.ErrorContains(t, err, "invalid client options: failed to read CA file")
+
+	_, err = New("https://localhost", "vyos", WithClientCertificate("missing.crt", "missing.key")).Request(ctx, "retrieve", nil)
+	assert.ErrorContains(t, err, "failed to load client certificate")
+
+	_, err = New("https://localhost", "vyos", WithProxy("://")).Request(ctx, "retrieve", nil)
+	assert.ErrorContains(t, err, "invalid proxy url")
+}
+
+func TestUnit_Options_Proxy(t *testing.T) {
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.Host == "vyos.invalid"
+		stub_response(http.StatusOK, okResponse)(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	client := New("http://vyos.invalid", "vyos", WithProxy(proxy.URL))
+	_, err := client.Request(context.Background(), "retrieve", nil)
+	assert.NoError(t, err)
+	assert.True(t, proxied)
+}
+
+// A Logger recording each message
+type recordingLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) record(level string, msg string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf("%s %s", level, msg))
+}
+
+func (l *recordingLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.record("debug", msg)
+}
+func (l *recordingLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.record("info", msg)
+}
+func (l *recordingLogger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.record("warn", msg)
+}
+func (l *recordingLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.record("error", msg)
+}
+
+func TestUnit_Options_RetryAndLogger(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(stub_flaky(t, 1, http.StatusServiceUnavailable, &attempts))
+	t.Cleanup(server.Close)
+
+	logger := &recordingLogger{}
+	client := NewWithClient(server.Client(), server.URL, "vyos",
+		WithRetryPolicy(fastRetryPolicy()),
+		WithLogger(logger),
+	)
+
+	_, err := client.Config.Show(context.Background(), "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, []string{"warn vyos api request failed", "debug vyos api request"}, logger.messages)
+}
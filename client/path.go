@@ -0,0 +1,135 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A path into the configuration tree, with one element per node, tag or value.
+//
+// Unlike a space separated string, elements may themselves contain spaces,
+// e.g. Path{"interfaces", "ethernet", "eth0", "description", "uplink to core"}.
+type Path []string
+
+// Create a path from individual elements
+func NewPath(elements ...string) Path {
+	return append(Path{}, elements...)
+}
+
+// Parse a path written in VyOS command syntax.
+//
+// Elements are separated by whitespace, and may be quoted with single quotes
+// (taken literally) or double quotes (where a backslash escapes the next
+// character), e.g. `system login banner pre-login 'Hello world'`.
+func ParsePath(s string) (Path, error) {
+	path := Path{}
+
+	var elem strings.Builder
+	inElem := false
+	quote := rune(0)
+	escaped := false
+
+	for _, c := range s {
+		switch {
+		case escaped:
+			elem.WriteRune(c)
+			escaped = false
+
+		case c == '\\' && quote != '\'':
+			inElem = true
+			escaped = true
+
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				elem.WriteRune(c)
+			}
+
+		case c == '\'' || c == '"':
+			inElem = true
+			quote = c
+
+		case c == ' ' || c == '\t' || c == '\n':
+			if inElem {
+				path = append(path, elem.String())
+				elem.Reset()
+				inElem = false
+			}
+
+		default:
+			inElem = true
+			elem.WriteRune(c)
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in path: %s", quote, s)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in path: %s", s)
+	}
+	if inElem {
+		path = append(path, elem.String())
+	}
+
+	return path, nil
+}
+
+// Return a new path with `elements` added to the end
+func (p Path) Append(elements ...string) Path {
+	res := make(Path, 0, len(p)+len(elements))
+	res = append(res, p...)
+	return append(res, elements...)
+}
+
+// Return the path with its last element removed. The parent of the root is the root.
+func (p Path) Parent() Path {
+	if len(p) == 0 {
+		return Path{}
+	}
+	return NewPath(p[:len(p)-1]...)
+}
+
+// Return the last element of the path, or "" for the root
+func (p Path) Last() string {
+	if len(p) == 0 {
+		return ""
+	}
+	return p[len(p)-1]
+}
+
+// Check whether `prefix` is equal to or an ancestor of this path
+func (p Path) HasPrefix(prefix Path) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i := range prefix {
+		if p[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Render the path in VyOS command syntax, quoting elements where needed so
+// that the result can be read back with ParsePath.
+func (p Path) String() string {
+	elems := make([]string, len(p))
+	for i, elem := range p {
+		elems[i] = quotePathElement(elem)
+	}
+	return strings.Join(elems, " ")
+}
+
+func quotePathElement(elem string) string {
+	if elem != "" && !strings.ContainsAny(elem, " \t\n'\"\\") {
+		return elem
+	}
+	if !strings.Contains(elem, "'") {
+		return "'" + elem + "'"
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(elem) + `"`
+}
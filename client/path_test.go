@@ -0,0 +1,89 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Path_Parse(t *testing.T) {
+	cases := map[string]Path{
+		"":                          {},
+		"system host-name":          {"system", "host-name"},
+		"  system   host-name  ":    {"system", "host-name"},
+		"description 'foo bar'":     {"description", "foo bar"},
+		`description "foo \"bar\""`: {"description", `foo "bar"`},
+		`description foo\ bar`:      {"description", "foo bar"},
+		`description ''`:            {"description", ""},
+		`banner 'a\b'`:              {"banner", `a\b`},
+		`key pre'quoted'post`:       {"key", "prequotedpost"},
+	}
+
+	for input, expected := range cases {
+		path, err := ParsePath(input)
+		assert.NoError(t, err, "parsing %q", input)
+		assert.Equal(t, expected, path, "parsing %q", input)
+	}
+
+	_, err := ParsePath("description 'foo")
+	assert.Error(t, err, "expected error on unterminated quote")
+	_, err = ParsePath(`description foo\`)
+	assert.Error(t, err, "expected error on trailing backslash")
+}
+
+func TestUnit_Path_String(t *testing.T) {
+	paths := []Path{
+		{},
+		{"system", "host-name"},
+		{"description", "foo bar"},
+		{"description", ""},
+		{"description", "it's"},
+		{"description", `say "hi" \o/`},
+	}
+
+	assert.Equal(t, "system host-name", paths[1].String())
+	assert.Equal(t, "description 'foo bar'", paths[2].String())
+
+	// Every rendered path must parse back to itself
+	for _, path := range paths {
+		parsed, err := ParsePath(path.String())
+		assert.NoError(t, err, "parsing %q", path.String())
+		assert.Equal(t, path, parsed)
+	}
+}
+
+func TestUnit_Path_AppendParent(t *testing.T) {
+	base := NewPath("interfaces", "ethernet")
+	eth0 := base.Append("eth0")
+	eth1 := base.Append("eth1")
+
+	// Appending must not alias the base path
+	assert.Equal(t, Path{"interfaces", "ethernet", "eth0"}, eth0)
+	assert.Equal(t, Path{"interfaces", "ethernet", "eth1"}, eth1)
+
+	assert.Equal(t, base, eth0.Parent())
+	assert.Equal(t, Path{}, Path{}.Parent())
+	assert.Equal(t, "eth0", eth0.Last())
+	assert.True(t, eth0.HasPrefix(base))
+	assert.False(t, base.HasPrefix(eth0))
+}
+
+func TestUnit_Path_SetWithSpaces(t *testing.T) {
+	var payload []any
+	client, ctx := make_stub_client(t, stub_api(t, func(endpoint string, data any) (int, string) {
+		payload = data.([]any)
+		return http.StatusOK, `{"success": true, "data": null, "error": null}`
+	}))
+
+	err := client.Config.Set(ctx, "interfaces ethernet eth0", map[string]any{
+		"description": "uplink to core",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"interfaces", "ethernet", "eth0", "description"}, payload[0].(map[string]any)["path"])
+	assert.Equal(t, "uplink to core", payload[0].(map[string]any)["value"])
+
+	err = client.Config.Set(ctx, "system login banner pre-login 'Hello world'", map[string]any{})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"system", "login", "banner", "pre-login", "Hello world"}, payload[0].(map[string]any)["path"])
+}
@@ -0,0 +1,258 @@
+package client
+
+import (
+	"sort"
+	"strings"
+)
+
+// The kind of a single change in a Plan
+type ChangeKind int
+
+const (
+	// A value or valueless node is added
+	ChangeAdd ChangeKind = iota
+	// A value, or a whole node with everything below it, is removed
+	ChangeRemove
+	// The single value of a leaf is replaced
+	ChangeModify
+)
+
+func (kind ChangeKind) String() string {
+	switch kind {
+	case ChangeAdd:
+		return "add"
+	case ChangeRemove:
+		return "remove"
+	case ChangeModify:
+		return "modify"
+	}
+	return "unknown"
+}
+
+// A single difference between two configuration trees
+type Change struct {
+	Kind ChangeKind
+	// Path of the leaf or node, relative to Plan.Path
+	Path Path
+	// The added value, the new value of a change, or the removed value.
+	// Empty for valueless nodes and removals of a whole node.
+	Value string
+	// The previous value of a change
+	Old string
+}
+
+// An ordered set of changes turning one configuration tree into another
+type Plan struct {
+	// Path the trees were compared at, prepended to every operation
+	Path    Path
+	Changes []Change
+}
+
+// Compute the changes needed to turn `current` into `desired`.
+//
+// Both trees may be any value accepted by Set, including nil for an absent
+// tree, and are compared leaf by leaf using FlattenPaths. Whole subtrees
+// missing from `desired` are removed at their topmost node, and a leaf whose
+// only value differs is reported as a modification. Changes are ordered by path.
+func Diff(current, desired any) (Plan, error) {
+	currentLeaves := []Leaf{}
+	if current != nil {
+		leaves, err := FlattenPaths(current)
+		if err != nil {
+			return Plan{}, err
+		}
+		currentLeaves = leaves
+	}
+
+	desiredLeaves := []Leaf{}
+	if desired != nil {
+		leaves, err := FlattenPaths(desired)
+		if err != nil {
+			return Plan{}, err
+		}
+		desiredLeaves = leaves
+	}
+
+	currentIndex := indexLeaves(currentLeaves)
+	desiredIndex := indexLeaves(desiredLeaves)
+
+	removed := []Leaf{}
+	for _, leaf := range currentLeaves {
+		if desiredIndex.hasLeaf(leaf) {
+			continue
+		}
+		// A valueless node which gained children is kept
+		if leaf.Value == "" && desiredIndex.hasNode(leaf.Path) {
+			continue
+		}
+		removed = append(removed, leaf)
+	}
+
+	added := []Leaf{}
+	for _, leaf := range desiredLeaves {
+		if currentIndex.hasLeaf(leaf) {
+			continue
+		}
+		// A valueless node which already has children exists
+		if leaf.Value == "" && currentIndex.hasNode(leaf.Path) {
+			continue
+		}
+		added = append(added, leaf)
+	}
+
+	changes := []Change{}
+	seen := map[string]bool{}
+
+	for _, leaf := range removed {
+		key := leaf.Path.String()
+
+		// A single value replaced by another single value is a change
+		if currentIndex.values[key] == 1 && desiredIndex.values[key] == 1 {
+			if add, ok := findLeaf(added, leaf.Path); ok {
+				changes = append(changes, Change{ChangeModify, leaf.Path, add.Value, leaf.Value})
+				seen[key] = true
+				continue
+			}
+		}
+
+		change := Change{ChangeRemove, leaf.Path, leaf.Value, ""}
+		for i := 1; i <= len(leaf.Path); i++ {
+			if !desiredIndex.hasNode(leaf.Path[:i]) {
+				change = Change{ChangeRemove, leaf.Path[:i], "", ""}
+				break
+			}
+		}
+
+		id := change.Path.String() + "\x00" + change.Value
+		if !seen[id] {
+			seen[id] = true
+			changes = append(changes, change)
+		}
+	}
+
+	for _, leaf := range added {
+		if seen[leaf.Path.String()] {
+			continue
+		}
+		changes = append(changes, Change{ChangeAdd, leaf.Path, leaf.Value, ""})
+	}
+
+	sortChanges(changes)
+	return Plan{Path{}, changes}, nil
+}
+
+func findLeaf(leaves []Leaf, path Path) (Leaf, bool) {
+	for _, leaf := range leaves {
+		if comparePaths(leaf.Path, path) == 0 {
+			return leaf, true
+		}
+	}
+	return Leaf{}, false
+}
+
+// Lookup tables over a flattened configuration tree
+type leafIndex struct {
+	// Every exact path and value
+	leaves map[string]bool
+	// Every node along the leaves' paths
+	nodes map[string]bool
+	// Number of values at each leaf path
+	values map[string]int
+}
+
+func indexLeaves(leaves []Leaf) leafIndex {
+	index := leafIndex{map[string]bool{}, map[string]bool{}, map[string]int{}}
+	for _, leaf := range leaves {
+		index.leaves[leaf.Path.String()+"\x00"+leaf.Value] = true
+		index.values[leaf.Path.String()]++
+		for i := 1; i <= len(leaf.Path); i++ {
+			index.nodes[leaf.Path[:i].String()] = true
+		}
+	}
+	return index
+}
+
+// Check whether the exact path and value are present
+func (index leafIndex) hasLeaf(leaf Leaf) bool {
+	return index.leaves[leaf.Path.String()+"\x00"+leaf.Value]
+}
+
+// Check whether the node at `path` is present, with or without children
+func (index leafIndex) hasNode(path Path) bool {
+	return index.nodes[path.String()]
+}
+
+// Stable sort by path, keeping the order of values within a path
+func sortChanges(changes []Change) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return comparePaths(changes[i].Path, changes[j].Path) < 0
+	})
+}
+
+func comparePaths(a, b Path) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := strings.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+// Check whether the plan has no changes
+func (plan Plan) IsEmpty() bool {
+	return len(plan.Changes) == 0
+}
+
+// Return the operations to send to `configure` to carry out the plan.
+//
+// All deletes are ordered before all sets. A change only sets the new value,
+// which replaces the old one without touching the leaf's comment.
+func (plan Plan) Operations() []Operation {
+	deletes := []Operation{}
+	sets := []Operation{}
+
+	for _, change := range plan.Changes {
+		path := plan.Path.Append(change.Path...)
+
+		switch change.Kind {
+		case ChangeAdd:
+			sets = append(sets, Operation{"set", path, change.Value})
+		case ChangeRemove:
+			deletes = append(deletes, Operation{"delete", path, change.Value})
+		case ChangeModify:
+			sets = append(sets, Operation{"set", path, change.Value})
+		}
+	}
+
+	return append(deletes, sets...)
+}
+
+// Render the operations of the plan in VyOS `set` command syntax, one line
+// per command, with sets prefixed by `+` and deletes by `-`.
+func (plan Plan) String() string {
+	var b strings.Builder
+	for _, op := range plan.Operations() {
+		if op.Op == "delete" {
+			b.WriteString("-")
+		} else {
+			b.WriteString("+")
+		}
+		b.WriteString(op.Op)
+		b.WriteString(" ")
+		b.WriteString(op.Path.String())
+		if op.Value != "" {
+			b.WriteString(" ")
+			b.WriteString(quoteValue(op.Value))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Quote a value the way `show configuration commands` does
+func quoteValue(value string) string {
+	if strings.Contains(value, "'") {
+		return quotePathElement(value)
+	}
+	return "'" + value + "'"
+}
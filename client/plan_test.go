@@ -0,0 +1,112 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Diff(t *testing.T) {
+	current := map[string]any{
+		"default-action": "drop",
+		"rule": map[string]any{
+			"10": map[string]any{
+				"action":      "accept",
+				"description": "allow ssh",
+				"protocol":    "tcp",
+			},
+			"20": map[string]any{
+				"action": "accept",
+				"state":  map[string]any{"established": map[string]any{}},
+			},
+		},
+		"name-server": []any{"1.1.1.1", "1.0.0.1"},
+	}
+	desired := map[string]any{
+		"default-action": "reject",
+		"rule": map[string]any{
+			"10": map[string]any{
+				"action":      "accept",
+				"description": "allow ssh",
+			},
+			"30": map[string]any{
+				"action": "drop",
+			},
+		},
+		"name-server": []string{"1.1.1.1", "9.9.9.9"},
+	}
+
+	plan, err := Diff(current, desired)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{ChangeModify, Path{"default-action"}, "reject", "drop"},
+		{ChangeRemove, Path{"name-server"}, "1.0.0.1", ""},
+		{ChangeAdd, Path{"name-server"}, "9.9.9.9", ""},
+		{ChangeRemove, Path{"rule", "10", "protocol"}, "", ""},
+		{ChangeRemove, Path{"rule", "20"}, "", ""},
+		{ChangeAdd, Path{"rule", "30", "action"}, "drop", ""},
+	}, plan.Changes)
+
+	plan.Path = Path{"firewall", "ipv4", "name", "WAN-IN"}
+	assert.Equal(t, []Operation{
+		{"delete", plan.Path.Append("name-server"), "1.0.0.1"},
+		{"delete", plan.Path.Append("rule", "10", "protocol"), ""},
+		{"delete", plan.Path.Append("rule", "20"), ""},
+		{"set", plan.Path.Append("default-action"), "reject"},
+		{"set", plan.Path.Append("name-server"), "9.9.9.9"},
+		{"set", plan.Path.Append("rule", "30", "action"), "drop"},
+	}, plan.Operations())
+
+	assert.Equal(t, ""+
+		"-delete firewall ipv4 name WAN-IN name-server '1.0.0.1'\n"+
+		"-delete firewall ipv4 name WAN-IN rule 10 protocol\n"+
+		"-delete firewall ipv4 name WAN-IN rule 20\n"+
+		"+set firewall ipv4 name WAN-IN default-action 'reject'\n"+
+		"+set firewall ipv4 name WAN-IN name-server '9.9.9.9'\n"+
+		"+set firewall ipv4 name WAN-IN rule 30 action 'drop'\n",
+		plan.String(),
+	)
+}
+
+func TestUnit_Diff_Unchanged(t *testing.T) {
+	tree := map[string]any{"host-name": "vyos", "option": map[string]any{"startup-beep": map[string]any{}}}
+	plan, err := Diff(tree, tree)
+	assert.NoError(t, err)
+	assert.True(t, plan.IsEmpty())
+	assert.Empty(t, plan.Operations())
+	assert.Equal(t, "", plan.String())
+}
+
+func TestUnit_Diff_Valueless(t *testing.T) {
+	// Adding children to a valueless node must not delete it first
+	plan, err := Diff(
+		map[string]any{"lo": map[string]any{}},
+		map[string]any{"lo": map[string]any{"address": "10.0.0.1/32"}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{{ChangeAdd, Path{"lo", "address"}, "10.0.0.1/32", ""}}, plan.Changes)
+
+	// Removing all children of a node only deletes the children
+	plan, err = Diff(
+		map[string]any{"lo": map[string]any{"address": "10.0.0.1/32"}},
+		map[string]any{"lo": map[string]any{}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{{ChangeRemove, Path{"lo", "address"}, "", ""}}, plan.Changes)
+}
+
+func TestUnit_Diff_Absent(t *testing.T) {
+	plan, err := Diff(nil, map[string]any{"description": "uplink to core"})
+	assert.NoError(t, err)
+	assert.Equal(t, "+set description 'uplink to core'\n", plan.String())
+
+	plan, err = Diff(map[string]any{"description": "it's"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []Operation{{"delete", Path{"description"}, ""}}, plan.Operations())
+	assert.Equal(t, "-delete description\n", plan.String())
+}
+
+func TestUnit_Diff_Error(t *testing.T) {
+	_, err := Diff(map[string]any{"foo": map[int]string{}}, nil)
+	assert.ErrorContains(t, err, "foo: invalid type")
+}
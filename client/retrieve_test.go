@@ -0,0 +1,95 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A stub retrieve endpoint serving a fixed set of leaves
+func stub_retrieve(t *testing.T, leaves map[string][]string) http.HandlerFunc {
+	return stub_api(t, func(endpoint string, data any) (int, string) {
+		req := data.(map[string]any)
+		components := []string{}
+		for _, c := range req["path"].([]any) {
+			components = append(components, c.(string))
+		}
+		path := strings.Join(components, " ")
+		values, found := leaves[path]
+
+		switch req["op"] {
+		case "exists":
+			if !found {
+				for leaf := range leaves {
+					found = found || strings.HasPrefix(leaf, path+" ")
+				}
+			}
+			if found {
+				return http.StatusOK, `{"success": true, "data": true, "error": null}`
+			}
+			return http.StatusOK, `{"success": true, "data": false, "error": null}`
+
+		case "returnValue":
+			if !found {
+				return http.StatusOK, `{"success": true, "data": null, "error": null}`
+			}
+			return http.StatusOK, `{"success": true, "data": "` + values[0] + `", "error": null}`
+
+		case "returnValues":
+			if !found {
+				return http.StatusOK, `{"success": true, "data": [], "error": null}`
+			}
+			return http.StatusOK, `{"success": true, "data": ["` + strings.Join(values, `", "`) + `"], "error": null}`
+		}
+
+		t.Errorf("unexpected op %s", req["op"])
+		return http.StatusBadRequest, `{"success": false, "data": null, "error": "bad op"}`
+	})
+}
+
+func TestUnit_Config_Exists(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_retrieve(t, map[string][]string{
+		"system host-name": {"vyos"},
+	}))
+
+	exists, err := client.Config.Exists(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.True(t, exists, "expected leaf to exist")
+
+	exists, err = client.Config.Exists(ctx, "system")
+	assert.NoError(t, err)
+	assert.True(t, exists, "expected node to exist")
+
+	exists, err = client.Config.Exists(ctx, "system domain-name")
+	assert.NoError(t, err)
+	assert.False(t, exists, "expected leaf to not exist")
+}
+
+func TestUnit_Config_ReturnValue(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_retrieve(t, map[string][]string{
+		"system host-name": {"vyos"},
+	}))
+
+	value, err := client.Config.ReturnValue(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "vyos", value)
+
+	_, err = client.Config.ReturnValue(ctx, "system domain-name")
+	assert.ErrorIs(t, err, ErrPathEmpty)
+}
+
+func TestUnit_Config_ReturnValues(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_retrieve(t, map[string][]string{
+		"system name-server": {"1.1.1.1", "1.0.0.1"},
+	}))
+
+	values, err := client.Config.ReturnValues(ctx, "system name-server")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.1.1.1", "1.0.0.1"}, values)
+
+	values, err = client.Config.ReturnValues(ctx, "system domain-search domain")
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+}
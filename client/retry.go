@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// Controls how failed requests are retried. The zero value never retries.
+type RetryPolicy struct {
+	// Total number of attempts per request, including the first. Values of
+	// one or less disable retries.
+	MaxAttempts int
+	// Delay before the first retry, doubled for each further retry
+	InitialBackoff time.Duration
+	// Upper bound on the delay between retries, if non-zero
+	MaxBackoff time.Duration
+	// Fraction of each delay which is randomized, between 0 and 1
+	Jitter float64
+
+	// Decide whether a failed attempt should be retried, given the HTTP
+	// status code (zero if no response was received) and the error.
+	// Defaults to RetryTransient.
+	RetryOn func(statusCode int, err error) bool
+	// Decide whether a request may be retried at all. Defaults to
+	// IsIdempotent, which excludes `configure`.
+	Retryable func(endpoint string, payload any) bool
+	// Also retry `configure` requests when Retryable is unset. A retried
+	// batch may be applied twice if the first attempt reached the router.
+	RetryConfigure bool
+}
+
+// A policy suitable for riding out commits and restarts of the HTTP service:
+// four attempts with exponential backoff from half a second up to ten.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Jitter:         0.2,
+	}
+}
+
+// Report whether a request only reads from the router, and can be safely
+// retried: `retrieve`, `show`, `container-image` with op `show`, and
+// GraphQL queries.
+func IsIdempotent(endpoint string, payload any) bool {
+	switch endpoint {
+	case "retrieve", "show":
+		return true
+	case "container-image":
+		obj, ok := payload.(map[string]any)
+		return ok && obj["op"] == "show"
+	case graphQLEndpoint:
+		req, ok := payload.(graphQLRequest)
+		return ok && !isMutation(req.Query)
+	}
+	return false
+}
+
+// Report whether a failure is likely transient: a 5xx or 429 response, or
+// a connection which was refused, reset or closed early.
+func RetryTransient(statusCode int, err error) bool {
+	if statusCode >= 500 || statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if statusCode != 0 {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// Set the policy for retrying failed requests. Defaults to never retrying.
+//
+// Must be called before the client is used.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+func (p RetryPolicy) retryable(endpoint string, payload any) bool {
+	if p.MaxAttempts <= 1 {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(endpoint, payload)
+	}
+	return IsIdempotent(endpoint, payload) || (p.RetryConfigure && endpoint == "configure")
+}
+
+func (p RetryPolicy) retryOn(err error) bool {
+	statusCode := 0
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		statusCode = apiErr.StatusCode
+	}
+
+	if p.RetryOn != nil {
+		return p.RetryOn(statusCode, err)
+	}
+	return RetryTransient(statusCode, err)
+}
+
+// Return the delay before retrying after `attempt` failed attempts. Without
+// a MaxBackoff the delay saturates at the longest time.Duration rather than
+// overflowing.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	limit := p.MaxBackoff
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < limit; i++ {
+		if delay > limit/2 {
+			delay = limit
+			break
+		}
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+
+	if p.Jitter > 0 {
+		jittered := float64(delay) * (1 + p.Jitter*(2*rand.Float64()-1))
+		if jittered >= math.MaxInt64 {
+			return math.MaxInt64
+		}
+		delay = time.Duration(jittered)
+	}
+	return delay
+}
+
+// Sleep before the next attempt, or until the context is done
+func (p RetryPolicy) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(p.backoff(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A stub failing the first `failures` requests with `status`
+func stub_flaky(t *testing.T, failures int, status int, attempts *int) http.HandlerFunc {
+	return stub_api(t, func(endpoint string, data any) (int, string) {
+		*attempts++
+		if *attempts <= failures {
+			return status, `{"detail": "Service Unavailable"}`
+		}
+		return http.StatusOK, `{"success": true, "data": {"host-name": "vyos"}, "error": null}`
+	})
+}
+
+func fastRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+}
+
+func TestUnit_Retry_Idempotent(t *testing.T) {
+	attempts := 0
+	client, ctx := make_stub_client(t, stub_flaky(t, 2, http.StatusServiceUnavailable, &attempts))
+	client.SetRetryPolicy(fastRetryPolicy())
+
+	resp, err := client.Config.Show(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "vyos", resp)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestUnit_Retry_GiveUp(t *testing.T) {
+	attempts := 0
+	client, ctx := make_stub_client(t, stub_flaky(t, 5, http.StatusBadGateway, &attempts))
+	client.SetRetryPolicy(fastRetryPolicy())
+
+	_, err := client.Config.Show(ctx, "system host-name")
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr), "expected an *APIError")
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestUnit_Retry_NotTransient(t *testing.T) {
+	attempts := 0
+	client, ctx := make_stub_client(t, stub_flaky(t, 5, http.StatusUnauthorized, &attempts))
+	client.SetRetryPolicy(fastRetryPolicy())
+
+	_, err := client.Config.Show(ctx, "system host-name")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestUnit_Retry_Configure(t *testing.T) {
+	attempts := 0
+	client, ctx := make_stub_client(t, stub_flaky(t, 1, http.StatusServiceUnavailable, &attempts))
+
+	// Not retried by default
+	policy := fastRetryPolicy()
+	client.SetRetryPolicy(policy)
+	err := client.Config.Set(ctx, "system host-name", "vyos")
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+
+	// Retried when opted in
+	attempts = 0
+	policy.RetryConfigure = true
+	client.SetRetryPolicy(policy)
+	err = client.Config.Set(ctx, "system host-name", "vyos")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestUnit_Retry_Disabled(t *testing.T) {
+	attempts := 0
+	client, ctx := make_stub_client(t, stub_flaky(t, 1, http.StatusServiceUnavailable, &attempts))
+
+	_, err := client.Config.Show(ctx, "system host-name")
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestUnit_Retry_ConnectionRefused(t *testing.T) {
+	// Find a port with nothing listening on it
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	client := NewWithClient(&http.Client{}, url, "vyos")
+
+	retried := 0
+	policy := fastRetryPolicy()
+	policy.RetryOn = func(statusCode int, err error) bool {
+		retried++
+		assert.Equal(t, 0, statusCode)
+		return RetryTransient(statusCode, err)
+	}
+	client.SetRetryPolicy(policy)
+
+	_, err := client.Config.Show(context.Background(), "system host-name")
+	assert.Error(t, err)
+	assert.Equal(t, 2, retried)
+}
+
+func TestUnit_Retry_ContextCanceled(t *testing.T) {
+	attempts := 0
+	client, _ := make_stub_client(t, stub_flaky(t, 5, http.StatusServiceUnavailable, &attempts))
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.Config.Show(ctx, "system host-name")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestUnit_Retry_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 800*time.Millisecond, policy.backoff(4))
+	assert.Equal(t, time.Second, policy.backoff(10))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.backoff(1)
+		assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+		assert.LessOrEqual(t, delay, 150*time.Millisecond)
+	}
+}
+
+// Without a MaxBackoff the delay saturates instead of overflowing
+func TestUnit_Retry_BackoffUnbounded(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 500 * time.Millisecond}
+	assert.Equal(t, 8*time.Second, policy.backoff(5))
+
+	previous := time.Duration(0)
+	for attempt := 1; attempt <= 1000; attempt++ {
+		delay := policy.backoff(attempt)
+		assert.GreaterOrEqual(t, delay, previous, "attempt %d", attempt)
+		previous = delay
+	}
+	assert.Equal(t, time.Duration(math.MaxInt64), policy.backoff(40))
+	assert.Equal(t, time.Duration(math.MaxInt64), policy.backoff(math.MaxInt))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		assert.Greater(t, policy.backoff(100), time.Duration(math.MaxInt64/4))
+	}
+}
+
+func TestUnit_Retry_IsIdempotent(t *testing.T) {
+	assert.True(t, IsIdempotent("retrieve", map[string]any{"op": "showConfig"}))
+	assert.True(t, IsIdempotent("show", map[string]any{"op": "show"}))
+	assert.True(t, IsIdempotent("container-image", map[string]any{"op": "show"}))
+	assert.False(t, IsIdempotent("container-image", map[string]any{"op": "add"}))
+	assert.False(t, IsIdempotent("configure", []Operation{}))
+	assert.False(t, IsIdempotent("config-file", map[string]any{"op": "save"}))
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reads the router's routing tables
+type RouteService struct{ client *Client }
+
+// A route in the output of `show ip route` or `show ipv6 route`
+type Route struct {
+	Prefix netip.Prefix
+	// Code of the protocol the route came from, such as "S" for static,
+	// "C" for connected or "B" for BGP
+	Protocol string
+	// Selected as the best route for the prefix (`>`)
+	Selected bool
+	// Installed in the kernel's forwarding table (`*`)
+	FIB bool
+	// Queued for installation (`q`), or rejected by the kernel (`r`)
+	Queued   bool
+	Rejected bool
+	// Zero for routes without them, such as connected routes
+	Distance int
+	Metric   int
+	NextHops []NextHop
+	// How long the route has existed, as shown, e.g. 00:10:23 or 1d02h03m
+	Age string
+}
+
+// A next hop of a Route
+type NextHop struct {
+	// Invalid for directly connected and unreachable routes
+	Address   netip.Addr
+	Interface string
+	// This next hop is installed in the forwarding table (`*`)
+	FIB               bool
+	DirectlyConnected bool
+	// Resolved through another route
+	Recursive bool
+	// The kind of unreachable route, e.g. "blackhole" or "reject", if any
+	Unreachable string
+	Weight      int
+}
+
+// Return the IPv4 routes in `vrf`, or the default VRF if empty
+func (svc *RouteService) IPv4(ctx context.Context, vrf string) ([]Route, error) {
+	return svc.show(ctx, "ip", vrf)
+}
+
+// Return the IPv6 routes in `vrf`, or the default VRF if empty
+func (svc *RouteService) IPv6(ctx context.Context, vrf string) ([]Route, error) {
+	return svc.show(ctx, "ipv6", vrf)
+}
+
+// Return the routes for exactly `prefix` in `vrf`, or the default VRF if
+// empty. The result is empty if the prefix is not in the routing table.
+func (svc *RouteService) Lookup(ctx context.Context, prefix netip.Prefix, vrf string) ([]Route, error) {
+	family := "ip"
+	if prefix.Addr().Is6() && !prefix.Addr().Is4In6() {
+		family = "ipv6"
+	}
+
+	routes, err := svc.show(ctx, family, vrf)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []Route{}
+	for _, route := range routes {
+		if route.Prefix == prefix.Masked() {
+			matches = append(matches, route)
+		}
+	}
+	return matches, nil
+}
+
+func (svc *RouteService) show(ctx context.Context, family string, vrf string) ([]Route, error) {
+	path := []string{family, "route"}
+	if vrf != "" {
+		path = append(path, "vrf", vrf)
+	}
+
+	output, err := svc.client.Show.Run(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return parseRoutes(output)
+}
+
+const routeFlags = `[>*qrbto=]*`
+
+// Newer FRR releases separate the flags of unselected routes with a space
+var routeLinePattern = regexp.MustCompile(`^(?P<protocol>[A-Za-z])(?P<flags>[>*qrbto= ]*?)\s+(?P<prefix>[0-9a-fA-F.:]+/\d+)(?:\s+\[(?P<distance>\d+)/(?P<metric>\d+)\])?\s+(?P<nexthop>.*)$`)
+var routeNextHopLinePattern = regexp.MustCompile(`^\s+(?P<flags>` + routeFlags + `)\s+(?P<nexthop>(?:via|is directly connected|unreachable).*)$`)
+var routeViaPattern = regexp.MustCompile(`^via (?P<address>\S+)(?P<recursive> \(recursive\))?`)
+var routeUnreachablePattern = regexp.MustCompile(`^unreachable(?: \((?P<kind>[^)]+)\))?`)
+var routeAgePattern = regexp.MustCompile(`^(?:\d{2}:\d{2}:\d{2}|(?:\d+[wdhms])+)$`)
+
+func parseRoutes(data string) ([]Route, error) {
+	routes := []Route{}
+
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// The legend runs until the first blank line
+		if strings.HasPrefix(line, "Codes:") {
+			for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+				i++
+			}
+			continue
+		}
+		// Section headers, like `VRF red:` or `IPv4 unicast VRF default:`
+		if strings.HasSuffix(line, ":") {
+			continue
+		}
+
+		if match, ok := matchStringNamed(routeLinePattern, line); ok {
+			prefix, err := netip.ParsePrefix(match["prefix"])
+			if err != nil {
+				return nil, unexpectedResponse("invalid route prefix in response from vyos api:\n%s", line)
+			}
+
+			route := Route{
+				Prefix:   prefix,
+				Protocol: match["protocol"],
+				Selected: strings.Contains(match["flags"], ">"),
+				FIB:      strings.Contains(match["flags"], "*"),
+				Queued:   strings.Contains(match["flags"], "q"),
+				Rejected: strings.Contains(match["flags"], "r"),
+			}
+			route.Distance, _ = strconv.Atoi(match["distance"])
+			route.Metric, _ = strconv.Atoi(match["metric"])
+
+			nexthop, age, err := parseNextHop(match["nexthop"], route.FIB)
+			if err != nil {
+				return nil, unexpectedResponse("invalid route in response from vyos api:\n%s", line)
+			}
+			route.NextHops = []NextHop{nexthop}
+			route.Age = age
+
+			routes = append(routes, route)
+			continue
+		}
+
+		if match, ok := matchStringNamed(routeNextHopLinePattern, line); ok && len(routes) > 0 {
+			nexthop, _, err := parseNextHop(match["nexthop"], strings.Contains(match["flags"], "*"))
+			if err != nil {
+				return nil, unexpectedResponse("invalid route in response from vyos api:\n%s", line)
+			}
+			last := &routes[len(routes)-1]
+			last.NextHops = append(last.NextHops, nexthop)
+			continue
+		}
+
+		return nil, unexpectedResponse("invalid route in response from vyos api:\n%s", line)
+	}
+
+	return routes, nil
+}
+
+// Parse a next hop like `via 192.0.2.1, eth0, weight 1, 00:10:23`, returning
+// the age at its end if there is one
+func parseNextHop(data string, fib bool) (NextHop, string, error) {
+	nexthop := NextHop{FIB: fib}
+	age := ""
+
+	parts := strings.Split(data, ", ")
+	switch first := parts[0]; {
+	case first == "is directly connected":
+		nexthop.DirectlyConnected = true
+
+	case strings.HasPrefix(first, "via "):
+		match, ok := matchStringNamed(routeViaPattern, first)
+		if !ok {
+			return nexthop, "", unexpectedResponse("invalid next hop: %s", first)
+		}
+		address, err := netip.ParseAddr(match["address"])
+		if err != nil {
+			return nexthop, "", unexpectedResponse("invalid next hop address: %s", match["address"])
+		}
+		nexthop.Address = address
+		nexthop.Recursive = match["recursive"] != ""
+
+	case strings.HasPrefix(first, "unreachable"):
+		match, _ := matchStringNamed(routeUnreachablePattern, first)
+		nexthop.Unreachable = match["kind"]
+		if nexthop.Unreachable == "" {
+			nexthop.Unreachable = "unreachable"
+		}
+
+	default:
+		return nexthop, "", unexpectedResponse("invalid next hop: %s", first)
+	}
+
+	for i, part := range parts[1:] {
+		switch {
+		case strings.HasPrefix(part, "weight "):
+			nexthop.Weight, _ = strconv.Atoi(strings.TrimPrefix(part, "weight "))
+		case routeAgePattern.MatchString(part):
+			age = part
+		case i == 0 && strings.TrimSpace(part) != "":
+			// Possibly followed by flags, as in `eth0 onlink`
+			nexthop.Interface = strings.Fields(part)[0]
+		}
+	}
+
+	return nexthop, age, nil
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// FRR 8, as in VyOS 1.4
+const ipRouteOutput = `Codes: K - kernel route, C - connected, S - static, R - RIP,
+       O - OSPF, I - IS-IS, B - BGP, E - EIGRP, N - NHRP,
+       T - Table, v - VNC, V - VNC-Direct, A - Babel, F - PBR,
+       f - OpenFabric,
+       > - selected route, * - FIB route, q - queued, r - rejected, b - backup
+       t - trapped, o - offload failure
+
+S>* 0.0.0.0/0 [1/0] via 192.0.2.254, eth0, weight 1, 00:10:23
+O   10.0.0.0/24 [110/10] is directly connected, eth1, weight 1, 00:05:00
+C>* 10.0.0.0/24 is directly connected, eth1, 00:10:25
+B>* 10.1.0.0/16 [20/0] via 10.0.0.2, eth1, weight 1, 1d02h03m
+  *                    via 10.0.0.3, eth2 onlink, weight 1, 1d02h03m
+S>  10.2.0.0/24 [1/0] via 10.0.0.9 (recursive), weight 1, 00:00:10
+  *                     via 10.0.0.2, eth1, weight 1, 00:00:10
+S>* 10.3.0.0/24 [1/0] unreachable (blackhole), weight 1, 00:00:05
+Sr  10.4.0.0/24 [1/0] via 10.9.9.9 inactive, weight 1, 00:00:01
+C * 192.0.2.0/24 is directly connected, eth0, 00:10:25
+C>* 192.0.2.0/24 is directly connected, eth0, 00:10:25
+`
+
+// FRR 7, as in VyOS 1.3, with a VRF header
+const ipRouteOutputVRF = `Codes: K - kernel route, C - connected, S - static, R - RIP,
+       O - OSPF, I - IS-IS, B - BGP, E - EIGRP, N - NHRP,
+       T - Table, v - VNC, V - VNC-Direct, A - Babel, D - SHARP,
+       F - PBR, f - OpenFabric,
+       > - selected route, * - FIB route, q - queued route, r - rejected route
+
+VRF red:
+K>* 0.0.0.0/0 [255/8192] unreachable (ICMP unreachable), 01w2d03h
+C>* 172.16.0.0/24 is directly connected, eth3, 01w2d03h
+`
+
+const ipv6RouteOutput = `Codes: K - kernel route, C - connected, S - static, R - RIPng,
+       O - OSPFv3, I - IS-IS, B - BGP, N - NHRP, T - Table,
+       v - VNC, V - VNC-Direct, A - Babel, F - PBR,
+       f - OpenFabric,
+       > - selected route, * - FIB route, q - queued, r - rejected, b - backup
+       t - trapped, o - offload failure
+
+S>* ::/0 [1/0] via fe80::1, eth0, weight 1, 00:10:23
+C>* 2001:db8::/64 is directly connected, eth0, 00:10:25
+C * fe80::/64 is directly connected, eth1, 00:10:25
+C>* fe80::/64 is directly connected, eth0, 00:10:25
+`
+
+func TestUnit_Routes_Parse(t *testing.T) {
+	routes, err := parseRoutes(ipRouteOutput)
+	assert.NoError(t, err)
+	assert.Len(t, routes, 9)
+
+	assert.Equal(t, Route{
+		Prefix:   netip.MustParsePrefix("0.0.0.0/0"),
+		Protocol: "S",
+		Selected: true,
+		FIB:      true,
+		Distance: 1,
+		NextHops: []NextHop{{
+			Address:   netip.MustParseAddr("192.0.2.254"),
+			Interface: "eth0",
+			FIB:       true,
+			Weight:    1,
+		}},
+		Age: "00:10:23",
+	}, routes[0])
+
+	// Not selected, with a metric
+	assert.Equal(t, Route{
+		Prefix:   netip.MustParsePrefix("10.0.0.0/24"),
+		Protocol: "O",
+		Distance: 110,
+		Metric:   10,
+		NextHops: []NextHop{{Interface: "eth1", DirectlyConnected: true, Weight: 1}},
+		Age:      "00:05:00",
+	}, routes[1])
+
+	// Connected, without distance and metric
+	assert.Equal(t, "C", routes[2].Protocol)
+	assert.Equal(t, 0, routes[2].Distance)
+	assert.Equal(t, []NextHop{{Interface: "eth1", FIB: true, DirectlyConnected: true}}, routes[2].NextHops)
+
+	// Multiple next hops
+	assert.Equal(t, []NextHop{
+		{Address: netip.MustParseAddr("10.0.0.2"), Interface: "eth1", FIB: true, Weight: 1},
+		{Address: netip.MustParseAddr("10.0.0.3"), Interface: "eth2", FIB: true, Weight: 1},
+	}, routes[3].NextHops)
+	assert.Equal(t, "1d02h03m", routes[3].Age)
+
+	// Recursive
+	assert.True(t, routes[4].Selected)
+	assert.False(t, routes[4].FIB)
+	assert.Equal(t, []NextHop{
+		{Address: netip.MustParseAddr("10.0.0.9"), Recursive: true, Weight: 1},
+		{Address: netip.MustParseAddr("10.0.0.2"), Interface: "eth1", FIB: true, Weight: 1},
+	}, routes[4].NextHops)
+
+	// Blackhole
+	assert.Equal(t, []NextHop{{Unreachable: "blackhole", FIB: true, Weight: 1}}, routes[5].NextHops)
+
+	// Rejected
+	assert.True(t, routes[6].Rejected)
+	assert.Equal(t, netip.MustParseAddr("10.9.9.9"), routes[6].NextHops[0].Address)
+
+	// Unselected duplicate
+	assert.False(t, routes[7].Selected)
+	assert.True(t, routes[7].FIB)
+	assert.Equal(t, routes[7].Prefix, routes[8].Prefix)
+}
+
+func TestUnit_Routes_ParseVRF(t *testing.T) {
+	routes, err := parseRoutes(ipRouteOutputVRF)
+	assert.NoError(t, err)
+	assert.Len(t, routes, 2)
+
+	assert.Equal(t, Route{
+		Prefix:   netip.MustParsePrefix("0.0.0.0/0"),
+		Protocol: "K",
+		Selected: true,
+		FIB:      true,
+		Distance: 255,
+		Metric:   8192,
+		NextHops: []NextHop{{Unreachable: "ICMP unreachable", FIB: true}},
+		Age:      "01w2d03h",
+	}, routes[0])
+	assert.Equal(t, "eth3", routes[1].NextHops[0].Interface)
+}
+
+func TestUnit_Routes_ParseIPv6(t *testing.T) {
+	routes, err := parseRoutes(ipv6RouteOutput)
+	assert.NoError(t, err)
+	assert.Len(t, routes, 4)
+
+	assert.Equal(t, netip.MustParsePrefix("::/0"), routes[0].Prefix)
+	assert.Equal(t, netip.MustParseAddr("fe80::1"), routes[0].NextHops[0].Address)
+	assert.Equal(t, "eth0", routes[0].NextHops[0].Interface)
+	assert.False(t, routes[2].Selected)
+	assert.True(t, routes[3].Selected)
+}
+
+func TestUnit_Routes_ParseInvalid(t *testing.T) {
+	routes, err := parseRoutes("")
+	assert.NoError(t, err)
+	assert.Empty(t, routes)
+
+	_, err = parseRoutes("% Unknown command: show ip route foo\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+
+	_, err = parseRoutes("S>* 10.0.0.0/24 [1/0] via foo, eth0\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+}
+
+func TestUnit_Routes_Show(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_show(t, map[string]string{
+		"ip route":         ipRouteOutput,
+		"ip route vrf red": ipRouteOutputVRF,
+		"ipv6 route":       ipv6RouteOutput,
+	}))
+
+	routes, err := client.Routes.IPv4(ctx, "")
+	assert.NoError(t, err)
+	assert.Len(t, routes, 9)
+
+	routes, err = client.Routes.IPv4(ctx, "red")
+	assert.NoError(t, err)
+	assert.Len(t, routes, 2)
+
+	routes, err = client.Routes.IPv6(ctx, "")
+	assert.NoError(t, err)
+	assert.Len(t, routes, 4)
+
+	routes, err = client.Routes.Lookup(ctx, netip.MustParsePrefix("192.0.2.0/24"), "")
+	assert.NoError(t, err)
+	assert.Len(t, routes, 2)
+
+	routes, err = client.Routes.Lookup(ctx, netip.MustParsePrefix("2001:db8::1/64"), "")
+	assert.NoError(t, err)
+	assert.Len(t, routes, 1)
+
+	routes, err = client.Routes.Lookup(ctx, netip.MustParsePrefix("10.99.0.0/16"), "")
+	assert.NoError(t, err)
+	assert.Empty(t, routes)
+
+	resp, err := client.Show.Parse(ctx, []string{"ipv6", "route"})
+	assert.NoError(t, err)
+	assert.Len(t, resp, 4)
+}
@@ -0,0 +1,276 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Runs operational mode `show` commands
+type ShowService struct {
+	client *Client
+
+	mutex   sync.RWMutex
+	parsers map[string]ShowParser
+}
+
+// Parses the output of a `show` command into a typed value
+type ShowParser func(output string) (any, error)
+
+func newShowService(client *Client) *ShowService {
+	svc := &ShowService{client: client, parsers: map[string]ShowParser{}}
+	svc.RegisterParser([]string{"version"}, func(output string) (any, error) {
+		return parseVersion(output)
+	})
+	svc.RegisterParser([]string{"system", "uptime"}, func(output string) (any, error) {
+		return parseUptime(output)
+	})
+	svc.RegisterParser([]string{"interfaces"}, func(output string) (any, error) {
+		return parseInterfaces(output)
+	})
+	for _, family := range []string{"ip", "ipv6"} {
+		svc.RegisterParser([]string{family, "route"}, func(output string) (any, error) {
+			return parseRoutes(output)
+		})
+	}
+	svc.RegisterParser([]string{"bgp", "summary"}, func(output string) (any, error) {
+		return parseBGPSummary(output)
+	})
+	svc.RegisterParser([]string{"bgp", "summary", "json"}, func(output string) (any, error) {
+		return parseBGPSummaryJSON(output)
+	})
+	svc.RegisterParser([]string{"dhcp", "server", "leases"}, func(output string) (any, error) {
+		return parseLeases(output)
+	})
+	svc.RegisterParser([]string{"system", "image"}, func(output string) (any, error) {
+		return parseSystemImages(output)
+	})
+	return svc
+}
+
+// Return the raw output of `show <path>`, e.g. Run(ctx, []string{"ip", "route"})
+func (svc *ShowService) Run(ctx context.Context, path []string) (string, error) {
+	if path == nil {
+		path = []string{}
+	}
+	resp, err := svc.client.Request(ctx, "show", map[string]any{
+		"op":   "show",
+		"path": path,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	output, ok := resp.(string)
+	if !ok {
+		return "", unexpectedResponse("expected string, got %T", resp)
+	}
+	return output, nil
+}
+
+// Register `parser` for the output of `show <path>`, replacing any parser
+// registered for it before. Parsers for `version`, `system uptime`,
+// `system image`, `interfaces`, `ip route`, `ipv6 route`, `bgp summary`
+// (with or without `json`) and `dhcp server leases` are registered by
+// default.
+func (svc *ShowService) RegisterParser(path []string, parser ShowParser) {
+	svc.mutex.Lock()
+	defer svc.mutex.Unlock()
+
+	svc.parsers[strings.Join(path, " ")] = parser
+}
+
+// Run `show <path>` and parse its output with the parser registered for it
+func (svc *ShowService) Parse(ctx context.Context, path []string) (any, error) {
+	svc.mutex.RLock()
+	parser, ok := svc.parsers[strings.Join(path, " ")]
+	svc.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no parser registered for show %s", strings.Join(path, " "))
+	}
+
+	output, err := svc.Run(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return parser(output)
+}
+
+// The output of `show version`
+type Version struct {
+	Version        string
+	ReleaseTrain   string
+	BuiltBy        string
+	BuiltOn        string
+	BuildUUID      string
+	BuildCommitID  string
+	Architecture   string
+	BootVia        string
+	SystemType     string
+	HardwareVendor string
+	HardwareModel  string
+	HardwareSerial string
+	HardwareUUID   string
+	// Every field of the output, by its name as shown
+	Fields map[string]string
+}
+
+// Return the running VyOS version and build information
+func (svc *ShowService) Version(ctx context.Context) (*Version, error) {
+	output, err := svc.Run(ctx, []string{"version"})
+	if err != nil {
+		return nil, err
+	}
+	return parseVersion(output)
+}
+
+var versionLinePattern = regexp.MustCompile(`^(?P<name>[A-Za-z][A-Za-z /]*?):\s*(?P<value>.*)$`)
+
+func parseVersion(data string) (*Version, error) {
+	fields := map[string]string{}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		match, ok := matchStringNamed(versionLinePattern, line)
+		if !ok {
+			continue
+		}
+		fields[match["name"]] = strings.TrimSpace(match["value"])
+	}
+
+	if fields["Version"] == "" {
+		return nil, unexpectedResponse("could not find version in response from vyos api:\n%s", data)
+	}
+
+	return &Version{
+		Version:        fields["Version"],
+		ReleaseTrain:   fields["Release train"],
+		BuiltBy:        fields["Built by"],
+		BuiltOn:        fields["Built on"],
+		BuildUUID:      fields["Build UUID"],
+		BuildCommitID:  fields["Build commit ID"],
+		Architecture:   fields["Architecture"],
+		BootVia:        fields["Boot via"],
+		SystemType:     fields["System type"],
+		HardwareVendor: fields["Hardware vendor"],
+		HardwareModel:  fields["Hardware model"],
+		HardwareSerial: fields["Hardware S/N"],
+		HardwareUUID:   fields["Hardware UUID"],
+		Fields:         fields,
+	}, nil
+}
+
+// The output of `show system uptime`
+type Uptime struct {
+	Uptime time.Duration
+	// Load averages over 1, 5 and 15 minutes
+	Load1  float64
+	Load5  float64
+	Load15 float64
+}
+
+// Return how long the router has been up, and its load averages
+func (svc *ShowService) Uptime(ctx context.Context) (*Uptime, error) {
+	output, err := svc.Run(ctx, []string{"system", "uptime"})
+	if err != nil {
+		return nil, err
+	}
+	return parseUptime(output)
+}
+
+// VyOS 1.4 and later
+var uptimeLinePattern = regexp.MustCompile(`^Uptime:\s*(?P<uptime>.+)$`)
+var uptimeUnitPattern = regexp.MustCompile(`(\d+)\s*([wdhms])`)
+var uptimeUnits = map[string]time.Duration{
+	"w": 7 * 24 * time.Hour,
+	"d": 24 * time.Hour,
+	"h": time.Hour,
+	"m": time.Minute,
+	"s": time.Second,
+}
+var loadLinePattern = regexp.MustCompile(`^(?P<minutes>1|5|15)\s+minutes?:\s*(?P<load>[\d.]+)%$`)
+
+// VyOS 1.3 and earlier, which show the output of uptime(1)
+var legacyUptimePattern = regexp.MustCompile(`up\s+(?:(?P<days>\d+)\s+days?,\s*)?(?:(?P<hours>\d+):(?P<minutes>\d+)|(?P<mins>\d+)\s+min),.*load average:\s*(?P<load1>[\d.]+),\s*(?P<load5>[\d.]+),\s*(?P<load15>[\d.]+)`)
+
+func parseUptime(data string) (*Uptime, error) {
+	if match, ok := matchStringNamed(legacyUptimePattern, data); ok {
+		return parseLegacyUptime(match)
+	}
+
+	uptime := &Uptime{}
+	foundUptime := false
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+
+		if match, ok := matchStringNamed(uptimeLinePattern, line); ok {
+			units := uptimeUnitPattern.FindAllStringSubmatch(match["uptime"], -1)
+			if units == nil {
+				return nil, unexpectedResponse("invalid uptime in response from vyos api:\n%s", line)
+			}
+			for _, unit := range units {
+				n, _ := strconv.Atoi(unit[1])
+				uptime.Uptime += time.Duration(n) * uptimeUnits[unit[2]]
+			}
+			foundUptime = true
+			continue
+		}
+
+		if match, ok := matchStringNamed(loadLinePattern, line); ok {
+			// Shown as a percentage of one CPU
+			load, err := strconv.ParseFloat(match["load"], 64)
+			if err != nil {
+				return nil, unexpectedResponse("invalid load average in response from vyos api:\n%s", line)
+			}
+			switch match["minutes"] {
+			case "1":
+				uptime.Load1 = load / 100
+			case "5":
+				uptime.Load5 = load / 100
+			case "15":
+				uptime.Load15 = load / 100
+			}
+		}
+	}
+
+	if !foundUptime {
+		return nil, unexpectedResponse("could not find uptime in response from vyos api:\n%s", data)
+	}
+	return uptime, nil
+}
+
+func parseLegacyUptime(match map[string]string) (*Uptime, error) {
+	atoi := func(s string) time.Duration {
+		n, _ := strconv.Atoi(s)
+		return time.Duration(n)
+	}
+	uptime := &Uptime{
+		Uptime: atoi(match["days"])*24*time.Hour +
+			atoi(match["hours"])*time.Hour +
+			atoi(match["minutes"])*time.Minute +
+			atoi(match["mins"])*time.Minute,
+	}
+
+	var err error
+	for _, load := range []struct {
+		field *float64
+		value string
+	}{
+		{&uptime.Load1, match["load1"]},
+		{&uptime.Load5, match["load5"]},
+		{&uptime.Load15, match["load15"]},
+	} {
+		*load.field, err = strconv.ParseFloat(load.value, 64)
+		if err != nil {
+			return nil, unexpectedResponse("invalid load average in response from vyos api: %s", load.value)
+		}
+	}
+	return uptime, nil
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A stub answering `show` requests with `outputs` by joined path
+func stub_show(t *testing.T, outputs map[string]string) http.HandlerFunc {
+	return stub_api(t, func(endpoint string, data any) (int, string) {
+		assert.Equal(t, "show", endpoint)
+		obj := data.(map[string]any)
+		assert.Equal(t, "show", obj["op"])
+
+		path := ""
+		for i, elem := range obj["path"].([]any) {
+			if i > 0 {
+				path += " "
+			}
+			path += elem.(string)
+		}
+
+		output, ok := outputs[path]
+		if !ok {
+			return http.StatusBadRequest, `{"success": false, "data": null, "error": "Invalid command"}`
+		}
+		body, _ := json.Marshal(map[string]any{"success": true, "data": output, "error": nil})
+		return http.StatusOK, string(body)
+	})
+}
+
+const versionOutput = `
+Version:          VyOS 1.4.0
+Release train:    sagitta
+
+Built by:         autobuild@vyos.net
+Built on:         Mon 12 Feb 2024 10:00 UTC
+Build UUID:       2c2bbbd0-4c1e-4eb2-8a47-48e0c1e8b1f1
+Build commit ID:  3fd2bb9e1ac2b8
+
+Architecture:     x86_64
+Boot via:         installed image
+System type:      KVM guest
+
+Hardware vendor:  QEMU
+Hardware model:   Standard PC (i440FX + PIIX, 1996)
+Hardware S/N:
+Hardware UUID:    00000000-0000-0000-0000-000000000000
+
+Copyright:        VyOS maintainers and contributors
+`
+
+func TestUnit_Show_Run(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_show(t, map[string]string{"ip route": "Codes: K - kernel route\n"}))
+
+	output, err := client.Show.Run(ctx, []string{"ip", "route"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Codes: K - kernel route\n", output)
+
+	_, err = client.Show.Run(ctx, []string{"foo"})
+	assert.ErrorContains(t, err, "Invalid command")
+}
+
+func TestUnit_Show_Version(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_show(t, map[string]string{"version": versionOutput}))
+
+	version, err := client.Show.Version(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "VyOS 1.4.0", version.Version)
+	assert.Equal(t, "sagitta", version.ReleaseTrain)
+	assert.Equal(t, "x86_64", version.Architecture)
+	assert.Equal(t, "Standard PC (i440FX + PIIX, 1996)", version.HardwareModel)
+	assert.Equal(t, "", version.HardwareSerial)
+	assert.Equal(t, "VyOS maintainers and contributors", version.Fields["Copyright"])
+
+	_, err = parseVersion("foo\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+}
+
+func TestUnit_Show_Uptime(t *testing.T) {
+	uptime, err := parseUptime("Uptime: 2d3h4m5s\n\nLoad averages:\n1  minute:   12.0%\n5  minutes:  5.5%\n15 minutes:  0.0%\n")
+	assert.NoError(t, err)
+	assert.Equal(t, &Uptime{
+		Uptime: 51*time.Hour + 4*time.Minute + 5*time.Second,
+		Load1:  0.12,
+		Load5:  0.055,
+		Load15: 0,
+	}, uptime)
+
+	uptime, err = parseUptime("Uptime: 1 week 2 days 5 minutes\n")
+	assert.NoError(t, err)
+	assert.Equal(t, 9*24*time.Hour+5*time.Minute, uptime.Uptime)
+
+	// VyOS 1.3
+	uptime, err = parseUptime(" 10:00:00 up 2 days,  3:04,  1 user,  load average: 0.00, 0.01, 0.05\n")
+	assert.NoError(t, err)
+	assert.Equal(t, &Uptime{Uptime: 51*time.Hour + 4*time.Minute, Load1: 0, Load5: 0.01, Load15: 0.05}, uptime)
+
+	uptime, err = parseUptime(" 10:00:00 up 45 min,  0 users,  load average: 1.50, 1.00, 0.50\n")
+	assert.NoError(t, err)
+	assert.Equal(t, 45*time.Minute, uptime.Uptime)
+	assert.Equal(t, 1.5, uptime.Load1)
+
+	_, err = parseUptime("foo\n")
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+}
+
+func TestUnit_Show_Parse(t *testing.T) {
+	client, ctx := make_stub_client(t, stub_show(t, map[string]string{
+		"version":        versionOutput,
+		"system uptime":  "Uptime: 5m\n",
+		"system storage": "Filesystem      Size  Used Avail Use% Mounted on\n",
+	}))
+
+	resp, err := client.Show.Parse(ctx, []string{"version"})
+	assert.NoError(t, err)
+	assert.Equal(t, "VyOS 1.4.0", resp.(*Version).Version)
+
+	resp, err = client.Show.Parse(ctx, []string{"system", "uptime"})
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, resp.(*Uptime).Uptime)
+
+	_, err = client.Show.Parse(ctx, []string{"system", "storage"})
+	assert.EqualError(t, err, "no parser registered for show system storage")
+
+	client.Show.RegisterParser([]string{"system", "storage"}, func(output string) (any, error) {
+		return len(output), nil
+	})
+	resp, err = client.Show.Parse(ctx, []string{"system", "storage"})
+	assert.NoError(t, err)
+	assert.Equal(t, 49, resp)
+}
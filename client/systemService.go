@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Reboots and powers off the router
+type SystemService struct {
+	client *Client
+
+	mutex sync.Mutex
+	// An immediate reboot was requested, and WaitReady has not seen the API
+	// go down since
+	rebooting bool
+}
+
+// When to reboot or power off. The zero value means now.
+type ShutdownOptions struct {
+	// Schedule for the next time the router's clock shows this hour and
+	// minute, so it should be in the router's time zone
+	At time.Time
+	// Schedule after this delay, rounded up to whole minutes
+	In time.Duration
+}
+
+// How often WaitReady polls the API
+var waitReadyInterval = 2 * time.Second
+
+// Reboot the router, now or at the time in `opts`
+func (svc *SystemService) Reboot(ctx context.Context, opts ShutdownOptions) error {
+	if err := svc.shutdown(ctx, "reboot", opts); err != nil {
+		return err
+	}
+
+	if opts.At.IsZero() && opts.In == 0 {
+		svc.mutex.Lock()
+		svc.rebooting = true
+		svc.mutex.Unlock()
+	}
+	return nil
+}
+
+// Power off the router, now or at the time in `opts`
+func (svc *SystemService) Poweroff(ctx context.Context, opts ShutdownOptions) error {
+	return svc.shutdown(ctx, "poweroff", opts)
+}
+
+// Cancel a scheduled reboot or poweroff
+func (svc *SystemService) CancelScheduled(ctx context.Context) error {
+	_, err := svc.client.Request(ctx, "reboot", map[string]any{
+		"op":   "reboot",
+		"path": []string{"cancel"},
+	})
+	return err
+}
+
+// Wait until the API answers requests, polling `retrieve` until `ctx` is
+// done. After an immediate Reboot through this client, first wait for the
+// API to go down, so that the router answering before it shuts down isn't
+// mistaken for it being back up.
+//
+// Returns immediately if the API rejects the key.
+func (svc *SystemService) WaitReady(ctx context.Context) error {
+	svc.mutex.Lock()
+	rebooting := svc.rebooting
+	svc.mutex.Unlock()
+
+	ticker := time.NewTicker(waitReadyInterval)
+	defer ticker.Stop()
+
+	for {
+		err := svc.client.Ping(ctx)
+		if errors.Is(err, ErrUnauthorized) {
+			return err
+		}
+
+		if rebooting && err != nil {
+			rebooting = false
+			svc.mutex.Lock()
+			svc.rebooting = false
+			svc.mutex.Unlock()
+		} else if !rebooting && err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				return fmt.Errorf("api did not go down after reboot: %w", ctx.Err())
+			}
+			return fmt.Errorf("api not ready: %w (last error: %s)", ctx.Err(), err.Error())
+		case <-ticker.C:
+		}
+	}
+}
+
+func (svc *SystemService) shutdown(ctx context.Context, op string, opts ShutdownOptions) error {
+	path, err := opts.path()
+	if err != nil {
+		return err
+	}
+
+	_, err = svc.client.Request(ctx, op, map[string]any{
+		"op":   op,
+		"path": path,
+	})
+	return err
+}
+
+// Return the arguments to `reboot` or `poweroff` for the options
+func (o ShutdownOptions) path() ([]string, error) {
+	switch {
+	case !o.At.IsZero() && o.In != 0:
+		return nil, errors.New("only one of At and In may be set")
+	case !o.At.IsZero():
+		return []string{"at", o.At.Format("15:04")}, nil
+	case o.In < 0:
+		return nil, fmt.Errorf("invalid delay %s", o.In)
+	case o.In > 0:
+		minutes := (o.In + time.Minute - 1) / time.Minute
+		return []string{"in", strconv.Itoa(int(minutes))}, nil
+	}
+	return []string{"now"}, nil
+}
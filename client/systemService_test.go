@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fast_wait_ready(t *testing.T) {
+	interval := waitReadyInterval
+	waitReadyInterval = time.Millisecond
+	t.Cleanup(func() { waitReadyInterval = interval })
+}
+
+func TestUnit_System_ShutdownOptions(t *testing.T) {
+	for _, tt := range []struct {
+		opts ShutdownOptions
+		path []string
+	}{
+		{ShutdownOptions{}, []string{"now"}},
+		{ShutdownOptions{At: time.Date(2024, 1, 2, 22, 5, 0, 0, time.UTC)}, []string{"at", "22:05"}},
+		{ShutdownOptions{In: 10 * time.Minute}, []string{"in", "10"}},
+		{ShutdownOptions{In: 90 * time.Second}, []string{"in", "2"}},
+	} {
+		path, err := tt.opts.path()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.path, path)
+	}
+
+	_, err := ShutdownOptions{At: time.Now(), In: time.Minute}.path()
+	assert.Error(t, err)
+	_, err = ShutdownOptions{In: -time.Minute}.path()
+	assert.Error(t, err)
+}
+
+func TestUnit_System_Requests(t *testing.T) {
+	requests := []any{}
+	client, ctx := make_stub_client(t, stub_api(t, func(endpoint string, data any) (int, string) {
+		requests = append(requests, []any{endpoint, data})
+		return http.StatusOK, `{"success": true, "data": "", "error": null}`
+	}))
+
+	assert.NoError(t, client.System.Reboot(ctx, ShutdownOptions{In: 5 * time.Minute}))
+	assert.NoError(t, client.System.Poweroff(ctx, ShutdownOptions{At: time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)}))
+	assert.NoError(t, client.System.CancelScheduled(ctx))
+	assert.NoError(t, client.System.Poweroff(ctx, ShutdownOptions{}))
+
+	assert.Equal(t, []any{
+		[]any{"reboot", map[string]any{"op": "reboot", "path": []any{"in", "5"}}},
+		[]any{"poweroff", map[string]any{"op": "poweroff", "path": []any{"at", "03:04"}}},
+		[]any{"reboot", map[string]any{"op": "reboot", "path": []any{"cancel"}}},
+		[]any{"poweroff", map[string]any{"op": "poweroff", "path": []any{"now"}}},
+	}, requests)
+
+	// Invalid options are not sent
+	assert.Error(t, client.System.Reboot(ctx, ShutdownOptions{At: time.Now(), In: time.Minute}))
+	assert.Len(t, requests, 4)
+}
+
+// Answer pings with the statuses in turn, repeating the last
+func stub_ping_statuses(t *testing.T, statuses ...int) (http.HandlerFunc, func() int) {
+	mutex := sync.Mutex{}
+	pings := 0
+	handler := stub_api(t, func(endpoint string, data any) (int, string) {
+		if endpoint != "retrieve" {
+			return http.StatusOK, `{"success": true, "data": "", "error": null}`
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		status := statuses[len(statuses)-1]
+		if pings < len(statuses) {
+			status = statuses[pings]
+		}
+		pings++
+
+		if status != http.StatusOK {
+			return status, `{"success": false, "data": null, "error": "unavailable"}`
+		}
+		return http.StatusOK, `{"success": true, "data": true, "error": null}`
+	})
+	return handler, func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return pings
+	}
+}
+
+func TestUnit_System_WaitReady(t *testing.T) {
+	fast_wait_ready(t)
+	handler, pings := stub_ping_statuses(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK)
+	client, ctx := make_stub_client(t, handler)
+
+	assert.NoError(t, client.System.WaitReady(ctx))
+	assert.Equal(t, 3, pings())
+}
+
+func TestUnit_System_WaitReadyAfterReboot(t *testing.T) {
+	fast_wait_ready(t)
+	handler, pings := stub_ping_statuses(t, http.StatusOK, http.StatusOK, http.StatusBadGateway, http.StatusOK)
+	client, ctx := make_stub_client(t, handler)
+
+	assert.NoError(t, client.System.Reboot(ctx, ShutdownOptions{}))
+	assert.NoError(t, client.System.WaitReady(ctx))
+	assert.Equal(t, 4, pings())
+
+	// Already back up
+	assert.NoError(t, client.System.WaitReady(ctx))
+	assert.Equal(t, 5, pings())
+}
+
+func TestUnit_System_WaitReadyUnauthorized(t *testing.T) {
+	fast_wait_ready(t)
+	handler, pings := stub_ping_statuses(t, http.StatusBadGateway, http.StatusForbidden)
+	client, ctx := make_stub_client(t, handler)
+
+	assert.ErrorIs(t, client.System.WaitReady(ctx), ErrUnauthorized)
+	assert.Equal(t, 2, pings())
+}
+
+func TestUnit_System_WaitReadyTimeout(t *testing.T) {
+	fast_wait_ready(t)
+	handler, _ := stub_ping_statuses(t, http.StatusBadGateway)
+	client, ctx := make_stub_client(t, handler)
+
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	err := client.System.WaitReady(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "api not ready")
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The columns of a table in the output of an operational command
+type tableLayout struct {
+	names  []string
+	starts []int
+}
+
+// Names in a header are separated by at least two spaces
+var tableHeaderPattern = regexp.MustCompile(`\S+(?: \S+)*`)
+var tableRulePattern = regexp.MustCompile(`-+`)
+var tableRuleLinePattern = regexp.MustCompile(`^\s*-+(?:\s+-+)*\s*$`)
+var tableCellPattern = regexp.MustCompile(`\S+`)
+
+// Locate the columns of a table from its `header` line, and its `rule` line
+// of dashes if there is one, which marks right-aligned columns better
+func newTableLayout(header string, rule string) tableLayout {
+	layout := tableLayout{}
+	for _, loc := range tableHeaderPattern.FindAllStringIndex(header, -1) {
+		layout.names = append(layout.names, header[loc[0]:loc[1]])
+		layout.starts = append(layout.starts, loc[0])
+	}
+
+	segments := tableRulePattern.FindAllStringIndex(rule, -1)
+	if len(segments) == len(layout.starts) {
+		for i, loc := range segments {
+			layout.starts[i] = loc[0]
+		}
+	}
+
+	return layout
+}
+
+// Check whether `line` is a rule of dashes under a table header
+func isTableRule(line string) bool {
+	return tableRuleLinePattern.MatchString(line)
+}
+
+// Split a row of the table into its cells, keyed by column name.
+//
+// Cells are assumed to contain no spaces, except in the last column. A cell
+// which overflows into the next column pushes the following cells along.
+func (l tableLayout) cells(line string) map[string]string {
+	cells := map[string]string{}
+	last := len(l.starts) - 1
+
+	col := -1
+	for _, loc := range tableCellPattern.FindAllStringIndex(line, -1) {
+		next := 0
+		for i, start := range l.starts {
+			if start <= loc[0] {
+				next = i
+			}
+		}
+		if next <= col {
+			next = col + 1
+		}
+		col = next
+
+		if col >= last {
+			cells[l.names[last]] = strings.TrimSpace(line[loc[0]:])
+			break
+		}
+		cells[l.names[col]] = line[loc[0]:loc[1]]
+	}
+
+	return cells
+}
+
+// Split a row of a table whose columns never overflow, such as one formatted
+// by tabulate, into its cells by column position. Unlike cells, this allows
+// spaces in any cell. Empty cells are omitted.
+func (l tableLayout) fixedCells(line string) map[string]string {
+	cells := map[string]string{}
+	for i, start := range l.starts {
+		if start >= len(line) {
+			break
+		}
+		end := len(line)
+		if i+1 < len(l.starts) && l.starts[i+1] < end {
+			end = l.starts[i+1]
+		}
+		if cell := strings.TrimSpace(line[start:end]); cell != "" {
+			cells[l.names[i]] = cell
+		}
+	}
+	return cells
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Table_Cells(t *testing.T) {
+	layout := newTableLayout(
+		"Name    IP Address    Size  Comment",
+		"------  ------------  ----  -------",
+	)
+	assert.Equal(t, []string{"Name", "IP Address", "Size", "Comment"}, layout.names)
+	assert.Equal(t, []int{0, 8, 22, 28}, layout.starts)
+
+	assert.Equal(t, map[string]string{
+		"Name":       "foo",
+		"IP Address": "10.0.0.1",
+		"Size":       "12",
+		"Comment":    "two  words",
+	}, layout.cells("foo     10.0.0.1        12  two  words"))
+
+	// Empty cells
+	assert.Equal(t, map[string]string{"IP Address": "10.0.0.2"},
+		layout.cells("        10.0.0.2"))
+	assert.Equal(t, map[string]string{"Name": "bar", "Size": "7"},
+		layout.cells("bar                      7"))
+
+	// Overflowing cells push the rest along
+	assert.Equal(t, map[string]string{
+		"Name":       "overflowing",
+		"IP Address": "10.0.0.3",
+		"Size":       "1",
+		"Comment":    "x",
+	}, layout.cells("overflowing 10.0.0.3 1 x"))
+}
+
+func TestUnit_Table_Rule(t *testing.T) {
+	assert.True(t, isTableRule("---------        ----------  ---"))
+	assert.True(t, isTableRule("  ----"))
+	assert.False(t, isTableRule("eth0  -  u/u"))
+	assert.False(t, isTableRule(""))
+}
+
+func TestUnit_Table_FixedCells(t *testing.T) {
+	layout := newTableLayout(
+		"Name    Lease start          Size  Comment",
+		"------  -------------------  ----  -------",
+	)
+
+	assert.Equal(t, map[string]string{
+		"Name":        "foo",
+		"Lease start": "2024/01/02 03:04:05",
+		"Size":        "12",
+		"Comment":     "two  words",
+	}, layout.fixedCells("foo     2024/01/02 03:04:05    12  two  words"))
+
+	// Empty and missing cells
+	assert.Equal(t, map[string]string{"Name": "bar", "Size": "7"},
+		layout.fixedCells("bar                            7"))
+	assert.Equal(t, map[string]string{"Name": "baz"}, layout.fixedCells("baz"))
+}
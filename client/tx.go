@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+)
+
+// A batch of operations submitted together in a single `configure` request.
+//
+// Operations are queued by Set, Delete and Comment, and nothing is sent to
+// the router until Commit. The first error encountered while queueing is
+// kept and returned by Commit, so calls can be chained without checking
+// each one.
+type Tx struct {
+	svc *ConfigService
+	ops []Operation
+	err error
+}
+
+// Start a new batch of operations
+func (svc *ConfigService) Begin() *Tx {
+	return &Tx{svc, []Operation{}, nil}
+}
+
+// Queue setting `value` at the specified path. See ConfigService.Set.
+func (tx *Tx) Set(path string, value any) *Tx {
+	p, err := ParsePath(path)
+	if err != nil {
+		return tx.fail(err)
+	}
+	return tx.SetPath(p, value)
+}
+
+// Queue setting `value` at the specified path. See ConfigService.Set.
+func (tx *Tx) SetPath(path Path, value any) *Tx {
+	if tx.err != nil {
+		return tx
+	}
+
+	flat, err := FlattenPaths(value)
+	if err != nil {
+		return tx.fail(err)
+	}
+
+	for _, leaf := range flat {
+		tx.ops = append(tx.ops, Operation{"set", path.Append(leaf.Path...), leaf.Value})
+	}
+	return tx
+}
+
+// Queue deleting values at the specified path. See ConfigService.Delete.
+func (tx *Tx) Delete(path string, value ...any) *Tx {
+	p, err := ParsePath(path)
+	if err != nil {
+		return tx.fail(err)
+	}
+	return tx.DeletePath(p, value...)
+}
+
+// Queue deleting values at the specified path. See ConfigService.Delete.
+func (tx *Tx) DeletePath(path Path, value ...any) *Tx {
+	if tx.err != nil {
+		return tx
+	}
+
+	if len(value) == 0 {
+		tx.ops = append(tx.ops, Operation{"delete", NewPath(path...), ""})
+		return tx
+	}
+
+	flat, err := FlattenPaths(value)
+	if err != nil {
+		return tx.fail(err)
+	}
+
+	for _, leaf := range flat {
+		tx.ops = append(tx.ops, Operation{"delete", path.Append(leaf.Path...), leaf.Value})
+	}
+	return tx
+}
+
+// Queue attaching a comment to the node at the specified path. An empty
+// comment clears it.
+func (tx *Tx) Comment(path string, comment string) *Tx {
+	p, err := ParsePath(path)
+	if err != nil {
+		return tx.fail(err)
+	}
+	return tx.CommentPath(p, comment)
+}
+
+// Queue attaching a comment to the node at the specified path
+func (tx *Tx) CommentPath(path Path, comment string) *Tx {
+	if tx.err != nil {
+		return tx
+	}
+
+	tx.ops = append(tx.ops, Operation{"comment", NewPath(path...), comment})
+	return tx
+}
+
+// Queue raw operations, such as those of a Plan
+func (tx *Tx) Add(ops ...Operation) *Tx {
+	if tx.err != nil {
+		return tx
+	}
+
+	tx.ops = append(tx.ops, ops...)
+	return tx
+}
+
+// Return a copy of the queued operations
+func (tx *Tx) Operations() []Operation {
+	return append([]Operation{}, tx.ops...)
+}
+
+// Return the first error encountered while queueing operations
+func (tx *Tx) Err() error {
+	return tx.err
+}
+
+// Send the queued operations in a single `configure` request.
+//
+// Nothing is sent if no operations are queued.
+func (tx *Tx) Commit(ctx context.Context) error {
+	if tx.err != nil {
+		return tx.err
+	}
+	if len(tx.ops) == 0 {
+		return nil
+	}
+
+	return tx.svc.configure(ctx, tx.ops)
+}
+
+// Send the queued operations with commit-confirm, and confirm them only if
+// `check` passes. See ConfigService.CommitConfirmWith.
+func (tx *Tx) CommitConfirm(ctx context.Context, minutes int, check HealthCheck) error {
+	if tx.err != nil {
+		return tx.err
+	}
+	if len(tx.ops) == 0 {
+		return nil
+	}
+
+	return tx.svc.CommitConfirmWith(ctx, minutes, tx.ops, check)
+}
+
+func (tx *Tx) fail(err error) *Tx {
+	if tx.err == nil {
+		tx.err = err
+	}
+	return tx
+}
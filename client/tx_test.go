@@ -0,0 +1,91 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Tx_Operations(t *testing.T) {
+	client, _ := make_stub_client(t, stub_response(http.StatusOK, `{"success": true, "data": null, "error": null}`))
+
+	tx := client.Config.Begin()
+	tx.Delete("firewall ipv4 name WAN-IN rule 10").
+		Set("firewall ipv4 name WAN-IN rule 20", map[string]any{
+			"action":      "accept",
+			"description": "allow ssh",
+		}).
+		Delete("system name-server", "1.1.1.1").
+		Comment("firewall ipv4 name WAN-IN rule 20", "managed by automation")
+
+	assert.NoError(t, tx.Err())
+	assert.Equal(t, []Operation{
+		{"delete", Path{"firewall", "ipv4", "name", "WAN-IN", "rule", "10"}, ""},
+		{"set", Path{"firewall", "ipv4", "name", "WAN-IN", "rule", "20", "action"}, "accept"},
+		{"set", Path{"firewall", "ipv4", "name", "WAN-IN", "rule", "20", "description"}, "allow ssh"},
+		{"delete", Path{"system", "name-server"}, "1.1.1.1"},
+		{"comment", Path{"firewall", "ipv4", "name", "WAN-IN", "rule", "20"}, "managed by automation"},
+	}, tx.Operations())
+
+	// The returned operations are a copy
+	tx.Operations()[0].Op = "set"
+	assert.Equal(t, "delete", tx.Operations()[0].Op)
+}
+
+func TestUnit_Tx_Commit(t *testing.T) {
+	var requests []any
+	client, ctx := make_stub_client(t, stub_api(t, func(endpoint string, data any) (int, string) {
+		requests = append(requests, data)
+		return http.StatusOK, `{"success": true, "data": null, "error": null}`
+	}))
+
+	// Everything is sent in one request
+	err := client.Config.Begin().
+		Delete("system host-name").
+		Set("system host-name", "router").
+		Commit(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{
+		[]any{
+			map[string]any{"op": "delete", "path": []any{"system", "host-name"}},
+			map[string]any{"op": "set", "path": []any{"system", "host-name"}, "value": "router"},
+		},
+	}, requests)
+
+	// Clearing a comment sends the empty value
+	requests = nil
+	err = client.Config.Begin().
+		Comment("interfaces ethernet eth0", "").
+		Commit(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{
+		[]any{
+			map[string]any{"op": "comment", "path": []any{"interfaces", "ethernet", "eth0"}, "value": ""},
+		},
+	}, requests)
+
+	// An empty batch sends nothing
+	requests = nil
+	err = client.Config.Begin().Commit(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, requests)
+}
+
+func TestUnit_Tx_Error(t *testing.T) {
+	var requests []any
+	client, ctx := make_stub_client(t, stub_api(t, func(endpoint string, data any) (int, string) {
+		requests = append(requests, data)
+		return http.StatusOK, `{"success": true, "data": null, "error": null}`
+	}))
+
+	tx := client.Config.Begin().
+		Set("system 'host-name", "router").
+		Set("system domain-name", map[int]string{}).
+		Set("system host-name", "router")
+
+	assert.ErrorContains(t, tx.Err(), "unterminated")
+	assert.Empty(t, tx.Operations())
+	assert.Equal(t, tx.Err(), tx.Commit(ctx))
+	assert.Nil(t, requests)
+}
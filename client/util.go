@@ -1,24 +1,36 @@
 package client
 
-import "fmt"
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// A single value in a flattened configuration tree
+type Leaf struct {
+	Path  Path
+	Value string
+}
 
-func flatten(result *[][]string, value any, path string) error {
+func flatten(result *[]Leaf, value any, path Path) error {
 	switch value.(type) {
 	case map[string]any:
 		tree := value.(map[string]any)
 
+		// Nil maps are absent, like in flattenValue, while empty ones are
+		// valueless nodes
+		if tree == nil {
+			return nil
+		}
 		if len(tree) == 0 {
-			*result = append(*result, []string{path, ""})
+			*result = append(*result, Leaf{path, ""})
 		}
 
-		for k, v := range tree {
-			subpath := path
-			if len(subpath) > 0 {
-				subpath += " "
-			}
-			subpath += k
-
-			err := flatten(result, v, subpath)
+		for _, k := range sortedKeys(tree) {
+			err := flatten(result, tree[k], path.Append(k))
 			if err != nil {
 				return err
 			}
@@ -27,18 +39,15 @@ func flatten(result *[][]string, value any, path string) error {
 	case map[string]string:
 		tree := value.(map[string]string)
 
+		if tree == nil {
+			return nil
+		}
 		if len(tree) == 0 {
-			*result = append(*result, []string{path, ""})
+			*result = append(*result, Leaf{path, ""})
 		}
 
-		for k, v := range tree {
-			subpath := path
-			if len(subpath) > 0 {
-				subpath += " "
-			}
-			subpath += k
-
-			err := flatten(result, v, subpath)
+		for _, k := range sortedKeys(tree) {
+			err := flatten(result, tree[k], path.Append(k))
 			if err != nil {
 				return err
 			}
@@ -65,18 +74,176 @@ func flatten(result *[][]string, value any, path string) error {
 		}
 
 	case string:
-		*result = append(*result, []string{path, value.(string)})
+		*result = append(*result, Leaf{path, value.(string)})
 
-	default:
+	case nil:
 		return fmt.Errorf("%s: invalid type %T", path, value)
+
+	default:
+		return flattenValue(result, reflect.ValueOf(value), path)
+	}
+
+	return nil
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	durationType      = reflect.TypeOf(time.Duration(0))
+)
+
+// Flatten arbitrary values by reflection, mirroring the mapping in Unmarshal
+func flattenValue(result *[]Leaf, v reflect.Value, path Path) error {
+	if v.Type() == durationType {
+		d := time.Duration(v.Int())
+		if d%time.Second != 0 {
+			return fmt.Errorf("%s: duration %s is not a whole number of seconds", path, d)
+		}
+		*result = append(*result, Leaf{path, strconv.FormatInt(int64(d/time.Second), 10)})
+		return nil
+	}
+
+	// Nil values are absent, even when they implement TextMarshaler, like a
+	// nil net.IP
+	if v.Kind() == reflect.Map && v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("%s: invalid type %s", path, v.Type())
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		*result = append(*result, Leaf{path, string(text)})
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return flattenValue(result, v.Elem(), path)
+
+	case reflect.Bool:
+		// True creates a valueless node, false leaves it out
+		if v.Bool() {
+			*result = append(*result, Leaf{path, ""})
+		}
+
+	case reflect.String:
+		*result = append(*result, Leaf{path, v.String()})
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		*result = append(*result, Leaf{path, strconv.FormatInt(v.Int(), 10)})
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		*result = append(*result, Leaf{path, strconv.FormatUint(v.Uint(), 10)})
+
+	case reflect.Float32, reflect.Float64:
+		*result = append(*result, Leaf{path, strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())})
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			err := flattenValue(result, v.Index(i), path)
+			if err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		if v.Len() == 0 {
+			*result = append(*result, Leaf{path, ""})
+		}
+
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			err := flattenValue(result, v.MapIndex(k), path.Append(k.String()))
+			if err != nil {
+				return err
+			}
+		}
+
+	case reflect.Struct:
+		count := len(*result)
+
+		for _, field := range structFields(v, false) {
+			// Plain struct fields are left out when zero, since there is no
+			// way to distinguish them from absent nodes. Use a pointer to
+			// create an empty node.
+			if field.value.Kind() == reflect.Struct && field.value.IsZero() {
+				continue
+			}
+			if field.omitempty && isEmptyValue(field.value) {
+				continue
+			}
+
+			err := flattenValue(result, field.value, path.Append(field.name))
+			if err != nil {
+				return err
+			}
+		}
+
+		// Like an empty map, an empty struct creates a valueless node
+		if len(*result) == count {
+			*result = append(*result, Leaf{path, ""})
+		}
+
+	default:
+		return fmt.Errorf("%s: invalid type %s", path, v.Type())
 	}
 
 	return nil
 }
 
-// Flatten a multi level object into a flat list of {key, value} pairs
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Flatten a multi level object into a flat list of leaves, each holding the
+// path relative to the root of `tree` and its value.
+//
+// Besides maps, lists and strings, `tree` may contain any value supported by
+// Unmarshal: structs with `vyos` tags (honoring `omitempty`), pointers, ints,
+// uints, floats, bools (true is a valueless node, false is left out),
+// time.Duration (in whole seconds), and any encoding.TextMarshaler such as
+// net.IP or netip.Prefix. Nil maps, slices and pointers are left out, while
+// empty maps are valueless nodes.
+func FlattenPaths(tree any) ([]Leaf, error) {
+	res := []Leaf{}
+	err := flatten(&res, tree, Path{})
+	return res, err
+}
+
+// Flatten a multi level object into a flat list of {key, value} pairs.
+//
+// Keys are rendered with Path.String, so any element containing spaces is
+// quoted and can be recovered with ParsePath.
 func Flatten(tree any) ([][]string, error) {
+	leaves, err := FlattenPaths(tree)
+	if err != nil {
+		return nil, err
+	}
+
 	res := [][]string{}
-	err := flatten(&res, tree, "")
+	for _, leaf := range leaves {
+		res = append(res, []string{leaf.Path.String(), leaf.Value})
+	}
 	return res, err
 }
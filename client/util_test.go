@@ -1,9 +1,13 @@
 package client
 
 import (
+	"net"
+	"net/netip"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 func checkFlattenResult(t *testing.T, tree map[string]any, expected [][]string) {
@@ -84,6 +88,29 @@ func TestUnit_Flatten_MapEmpty(t *testing.T) {
 		},
 	)
 }
+
+// Nil maps are absent whichever path flattens them, unlike empty ones
+func TestUnit_Flatten_MapNil(t *testing.T) {
+	checkFlattenResult(t,
+		map[string]any{
+			"any":    map[string]any(nil),
+			"string": map[string]string(nil),
+			"int":    map[string]int(nil),
+			"empty":  map[string]int{},
+		},
+		[][]string{
+			{"empty", ""},
+		},
+	)
+
+	checkFlattenError(t,
+		map[string]any{
+			"foo": map[int]string(nil),
+		},
+		"foo: invalid type",
+	)
+}
+
 func TestUnit_Flatten_MapErrorWithAny(t *testing.T) {
 	checkFlattenError(t,
 		map[string]any{
@@ -133,3 +160,170 @@ func TestUnit_Flatten_ArrayEmpty(t *testing.T) {
 		[][]string{},
 	)
 }
+
+func TestUnit_Flatten_KeyWithSpaces(t *testing.T) {
+	checkFlattenResult(t,
+		map[string]any{
+			"rule": map[string]any{
+				"allow ssh": map[string]any{},
+			},
+		},
+		[][]string{
+			{"rule 'allow ssh'", ""},
+		},
+	)
+
+	leaves, err := FlattenPaths(map[string]any{
+		"rule": map[string]any{
+			"allow ssh": map[string]any{},
+		},
+	})
+	if err != nil {
+		t.Errorf("unexpected error: '%s'", err.Error())
+	} else if !reflect.DeepEqual(leaves, []Leaf{{Path{"rule", "allow ssh"}, ""}}) {
+		t.Errorf("unexpected result: %v", leaves)
+	}
+}
+
+func TestUnit_Flatten_MapSorted(t *testing.T) {
+	checkFlattenResult(t,
+		map[string]any{
+			"c": "3",
+			"a": "1",
+			"b": "2",
+		},
+		[][]string{
+			{"a", "1"},
+			{"b", "2"},
+			{"c", "3"},
+		},
+	)
+}
+
+func TestUnit_Flatten_Typed(t *testing.T) {
+	checkFlattenResult(t,
+		map[string]any{
+			"mtu":     1500,
+			"vlan":    uint16(10),
+			"disable": true,
+			"enable":  false,
+			"address": []netip.Prefix{netip.MustParsePrefix("10.0.0.1/24")},
+			"gateway": net.ParseIP("10.0.0.254"),
+			"timeout": 90 * time.Second,
+			"servers": map[string]int{"a": 1},
+			"ptr":     &[]string{"x"},
+			"nilptr":  (*string)(nil),
+		},
+		[][]string{
+			{"address", "10.0.0.1/24"},
+			{"disable", ""},
+			{"gateway", "10.0.0.254"},
+			{"mtu", "1500"},
+			{"ptr", "x"},
+			{"servers a", "1"},
+			{"timeout", "90"},
+			{"vlan", "10"},
+		},
+	)
+
+	checkFlattenError(t,
+		map[string]any{
+			"timeout": 1500 * time.Millisecond,
+		},
+		"timeout: duration 1.5s is not a whole number of seconds",
+	)
+}
+
+func TestUnit_Flatten_Struct(t *testing.T) {
+	type user struct {
+		FullName string `vyos:"full-name,omitempty"`
+		Uid      int    `vyos:"uid,omitempty"`
+	}
+	type system struct {
+		HostName    string          `vyos:"host-name"`
+		DomainName  string          `vyos:"domain-name,omitempty"`
+		NameServers []string        `vyos:"name-server"`
+		Users       map[string]user `vyos:"user"`
+		Option      struct {
+			StartupBeep bool `vyos:"startup-beep"`
+		} `vyos:"option"`
+		Ignored string `vyos:"-"`
+	}
+
+	checkFlattenResult(t,
+		map[string]any{
+			"system": system{
+				HostName:    "vyos",
+				NameServers: []string{"1.1.1.1", "1.0.0.1"},
+				Users: map[string]user{
+					"vyos":  {FullName: "VyOS User", Uid: 1000},
+					"admin": {},
+				},
+				Ignored: "foo",
+			},
+		},
+		[][]string{
+			{"system host-name", "vyos"},
+			{"system name-server", "1.1.1.1"},
+			{"system name-server", "1.0.0.1"},
+			{"system user admin", ""},
+			{"system user vyos full-name", "VyOS User"},
+			{"system user vyos uid", "1000"},
+		},
+	)
+}
+
+// Nil values which implement TextMarshaler are absent, like zero ones
+func TestUnit_Flatten_NilTextMarshaler(t *testing.T) {
+	type route struct {
+		Gateway net.IP       `vyos:"gateway"`
+		Address netip.Addr   `vyos:"address"`
+		Prefix  netip.Prefix `vyos:"prefix"`
+		Mask    net.IPMask   `vyos:"mask"`
+		Metric  int          `vyos:"metric"`
+	}
+
+	checkFlattenResult(t,
+		map[string]any{
+			"route": route{Metric: 10},
+			"ip":    net.IP(nil),
+		},
+		[][]string{
+			{"route metric", "10"},
+		},
+	)
+}
+
+// Values decoded with Unmarshal should flatten back to the original tree
+func TestUnit_Flatten_UnmarshalRoundTrip(t *testing.T) {
+	tree := map[string]any{
+		"host-name":   "vyos",
+		"name-server": []any{"1.1.1.1", "1.0.0.1"},
+		"option": map[string]any{
+			"reboot-on-panic": map[string]any{},
+		},
+		"login": map[string]any{
+			"user": map[string]any{
+				"vyos": map[string]any{"full-name": "VyOS User", "uid": "1000"},
+			},
+		},
+	}
+
+	var system testSystem
+	if err := Unmarshal(tree, &system); err != nil {
+		t.Fatalf("unexpected error: '%s'", err.Error())
+	}
+
+	expected, _ := Flatten(tree)
+	flat, err := Flatten(system)
+	sortPairs := func(pairs [][]string) {
+		sort.SliceStable(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	}
+	sortPairs(expected)
+	sortPairs(flat)
+	if err != nil {
+		t.Errorf("unexpected error: '%s'", err.Error())
+	} else if !reflect.DeepEqual(flat, expected) {
+		t.Errorf("unexpected result: %v, expected: %v", flat, expected)
+	}
+}
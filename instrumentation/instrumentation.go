@@ -0,0 +1,75 @@
+// Package instrumentation provides client middleware which traces VyOS API
+// requests with OpenTelemetry and measures them with Prometheus.
+//
+// It lives in its own module, so that the client package does not depend
+// on either. Add the middleware when creating a client:
+//
+//	metrics, err := instrumentation.NewMetrics(prometheus.DefaultRegisterer)
+//	c := client.New(url, key, client.WithMiddleware(
+//		instrumentation.Tracing(nil),
+//		metrics.Middleware(),
+//	))
+package instrumentation
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// The operation and path of a request payload, where they can be determined
+type request struct {
+	op   string
+	path []string
+}
+
+// Describe the payload of a request.
+//
+// Batches of several operations report the distinct ops they contain, joined
+// with commas such as "delete,set", and no path.
+func describe(payload any) request {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return request{}
+	}
+
+	var batch []operation
+	if json.Unmarshal(data, &batch) == nil {
+		return describeBatch(batch)
+	}
+
+	var single struct {
+		operation
+		// Commit-confirm wraps a batch in an object
+		Commands []operation `json:"commands"`
+	}
+	if json.Unmarshal(data, &single) != nil {
+		return request{}
+	}
+	if single.Commands != nil {
+		return describeBatch(single.Commands)
+	}
+	return request{single.Op, single.Path}
+}
+
+type operation struct {
+	Op   string   `json:"op"`
+	Path []string `json:"path"`
+}
+
+func describeBatch(batch []operation) request {
+	if len(batch) == 1 {
+		return request{batch[0].Op, batch[0].Path}
+	}
+
+	seen := map[string]bool{}
+	ops := []string{}
+	for _, op := range batch {
+		if !seen[op.Op] {
+			seen[op.Op] = true
+			ops = append(ops, op.Op)
+		}
+	}
+	sort.Strings(ops)
+	return request{strings.Join(ops, ","), nil}
+}
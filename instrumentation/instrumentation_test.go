@@ -0,0 +1,97 @@
+package instrumentation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/foltik/vyos-client-go/client"
+	"github.com/foltik/vyos-client-go/vyostest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func make_client(t *testing.T, middleware ...client.Middleware) (*client.Client, context.Context) {
+	server := vyostest.NewServer()
+	t.Cleanup(server.Close)
+
+	c := client.NewWithClient(server.Client(), server.URL, vyostest.DefaultKey,
+		client.WithMiddleware(middleware...))
+	return c, context.Background()
+}
+
+func TestUnit_Describe(t *testing.T) {
+	assert.Equal(t, request{"showConfig", []string{"system"}},
+		describe(map[string]any{"op": "showConfig", "path": []string{"system"}}))
+
+	assert.Equal(t, request{"set", []string{"system", "host-name"}},
+		describe([]client.Operation{{Op: "set", Path: client.NewPath("system", "host-name"), Value: "vyos"}}))
+
+	assert.Equal(t, request{"delete,set", nil}, describe([]client.Operation{
+		{Op: "set", Path: client.NewPath("system", "host-name"), Value: "vyos"},
+		{Op: "delete", Path: client.NewPath("system", "domain-name")},
+		{Op: "set", Path: client.NewPath("system", "time-zone"), Value: "UTC"},
+	}))
+
+	assert.Equal(t, request{"set", []string{"system", "host-name"}}, describe(map[string]any{
+		"commands":     []client.Operation{{Op: "set", Path: client.NewPath("system", "host-name"), Value: "vyos"}},
+		"confirm_time": 5,
+	}))
+
+	assert.Equal(t, request{}, describe("foo"))
+}
+
+func TestUnit_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	c, ctx := make_client(t, Tracing(provider))
+
+	_, err := c.Config.Show(ctx, "system host-name")
+	assert.NoError(t, err)
+	err = c.Config.Delete(ctx, "system domain-name")
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	assert.Equal(t, "vyos retrieve", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		EndpointKey.String("retrieve"),
+		OpKey.String("showConfig"),
+		PathKey.String("system host-name"),
+		SuccessKey.Bool(true),
+	}, spans[0].Attributes())
+
+	assert.Equal(t, "vyos configure", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Contains(t, spans[1].Attributes(), SuccessKey.Bool(false))
+	assert.Contains(t, spans[1].Attributes(), OpKey.String("delete"))
+}
+
+func TestUnit_Metrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := NewMetrics(registry)
+	assert.NoError(t, err)
+	c, ctx := make_client(t, metrics.Middleware())
+
+	_, err = c.Config.Show(ctx, "system host-name")
+	assert.NoError(t, err)
+	_, err = c.Config.Show(ctx, "system name-server")
+	assert.NoError(t, err)
+	err = c.Config.Delete(ctx, "system domain-name")
+	assert.Error(t, err)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.Requests.WithLabelValues("retrieve", "showConfig", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Requests.WithLabelValues("configure", "delete", "error")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.InFlight.WithLabelValues("retrieve")))
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.Duration))
+
+	// Registering twice fails
+	_, err = NewMetrics(registry)
+	assert.Error(t, err)
+}
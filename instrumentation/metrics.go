@@ -0,0 +1,82 @@
+package instrumentation
+
+import (
+	"context"
+	"time"
+
+	"github.com/foltik/vyos-client-go/client"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus metrics of VyOS API requests, labeled by endpoint and op
+type Metrics struct {
+	// Total requests, additionally labeled with a `result` of "success" or
+	// "error"
+	Requests *prometheus.CounterVec
+	// Request latency in seconds, including retries
+	Duration *prometheus.HistogramVec
+	// Requests currently in flight
+	InFlight *prometheus.GaugeVec
+}
+
+// Create the metrics and register them with `registerer`, unless it is nil
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		Requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "vyos",
+			Subsystem: "api",
+			Name:      "requests_total",
+			Help:      "Total number of requests to the VyOS API.",
+		}, []string{"endpoint", "op", "result"}),
+
+		Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "vyos",
+			Subsystem: "api",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of requests to the VyOS API, including retries.",
+			Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"endpoint", "op"}),
+
+		InFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "vyos",
+			Subsystem: "api",
+			Name:      "requests_in_flight",
+			Help:      "Number of requests to the VyOS API in progress.",
+		}, []string{"endpoint"}),
+	}
+
+	if registerer != nil {
+		for _, c := range []prometheus.Collector{m.Requests, m.Duration, m.InFlight} {
+			if err := registerer.Register(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Return a middleware recording each request in the metrics
+func (m *Metrics) Middleware() client.Middleware {
+	return func(next client.RequestFunc) client.RequestFunc {
+		return func(ctx context.Context, endpoint string, payload any) (any, error) {
+			op := describe(payload).op
+
+			inFlight := m.InFlight.WithLabelValues(endpoint)
+			inFlight.Inc()
+			start := time.Now()
+
+			resp, err := next(ctx, endpoint, payload)
+
+			m.Duration.WithLabelValues(endpoint, op).Observe(time.Since(start).Seconds())
+			inFlight.Dec()
+			result := "success"
+			if err != nil {
+				result = "error"
+			}
+			m.Requests.WithLabelValues(endpoint, op, result).Inc()
+
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package instrumentation
+
+import (
+	"context"
+	"strings"
+
+	"github.com/foltik/vyos-client-go/client"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The instrumentation name reported to tracer providers
+const tracerName = "github.com/foltik/vyos-client-go/instrumentation"
+
+// Span attributes recorded for each request
+const (
+	EndpointKey = attribute.Key("vyos.endpoint")
+	OpKey       = attribute.Key("vyos.op")
+	PathKey     = attribute.Key("vyos.path")
+	SuccessKey  = attribute.Key("vyos.success")
+)
+
+// Return a middleware recording a client span for each request, named
+// after its endpoint, e.g. "vyos retrieve".
+//
+// Spans are created by `provider`, or the global tracer provider if nil.
+// Retries of a request are part of its span.
+func Tracing(provider trace.TracerProvider) client.Middleware {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	tracer := provider.Tracer(tracerName)
+
+	return func(next client.RequestFunc) client.RequestFunc {
+		return func(ctx context.Context, endpoint string, payload any) (any, error) {
+			req := describe(payload)
+			attrs := []attribute.KeyValue{EndpointKey.String(endpoint)}
+			if req.op != "" {
+				attrs = append(attrs, OpKey.String(req.op))
+			}
+			if req.path != nil {
+				attrs = append(attrs, PathKey.String(strings.Join(req.path, " ")))
+			}
+
+			ctx, span := tracer.Start(ctx, "vyos "+endpoint,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			resp, err := next(ctx, endpoint, payload)
+			span.SetAttributes(SuccessKey.Bool(err == nil))
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return resp, err
+		}
+	}
+}
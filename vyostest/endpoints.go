@@ -0,0 +1,425 @@
+package vyostest
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const pathEmptyMessage = "Configuration under specified path is empty\n"
+
+func (s *Server) retrieve(data any) (any, *apiError) {
+	obj, err := fields(data)
+	if err != nil {
+		return nil, err
+	}
+	op, err := stringField(obj, "op")
+	if err != nil {
+		return nil, err
+	}
+	p, err := pathField(obj, "path")
+	if err != nil {
+		return nil, err
+	}
+
+	n := s.running.lookup(p)
+
+	switch op {
+	case "showConfig":
+		if n == nil || (len(p) > 0 && n.isEmpty()) {
+			return nil, badRequest(pathEmptyMessage)
+		}
+		if n.isLeaf() {
+			return map[string]any{p[len(p)-1]: n.render()}, nil
+		}
+		return n.render(), nil
+
+	case "exists":
+		return n != nil, nil
+
+	case "returnValue":
+		if n == nil || !n.isLeaf() {
+			return nil, nil
+		}
+		return n.values[0], nil
+
+	case "returnValues":
+		if n == nil {
+			return []any{}, nil
+		}
+		values := []any{}
+		for _, v := range n.values {
+			values = append(values, v)
+		}
+		return values, nil
+	}
+
+	return nil, badRequest("\"%s\" is not a valid operation", op)
+}
+
+func (s *Server) configure(data any) (any, *apiError) {
+	var commands []any
+	confirmTime := 0
+
+	switch data := data.(type) {
+	case []any:
+		commands = data
+
+	case map[string]any:
+		if data["op"] == "confirm" {
+			if s.pending == nil {
+				return nil, badRequest("No confirmation required")
+			}
+			s.pending = nil
+			return "Reboot timer stopped", nil
+		}
+
+		if cmds, ok := data["commands"]; ok {
+			list, ok := cmds.([]any)
+			if !ok {
+				return nil, badRequest("Malformed request: commands must be a list")
+			}
+			commands = list
+
+			if t, ok := data["confirm_time"].(float64); ok {
+				confirmTime = int(t)
+			}
+		} else {
+			commands = []any{data}
+		}
+
+	default:
+		return nil, badRequest("Malformed request: expected an object or list")
+	}
+
+	// Apply everything to a copy, and only keep it if all operations succeed
+	session := s.running.clone()
+	for _, command := range commands {
+		obj, err := fields(command)
+		if err != nil {
+			return nil, err
+		}
+		op, err := stringField(obj, "op")
+		if err != nil {
+			return nil, err
+		}
+		p, err := pathField(obj, "path")
+		if err != nil {
+			return nil, err
+		}
+		value, err := stringField(obj, "value")
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case "set":
+			err = s.set(session, p, value)
+		case "delete":
+			err = s.delete(session, p, value)
+		case "comment":
+			err = s.comment(session, p, value)
+		default:
+			err = badRequest("\"%s\" is not a valid operation", op)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if confirmTime > 0 {
+		s.pending = s.running
+	}
+	s.running = session
+	return nil, nil
+}
+
+func (s *Server) isMultiValue(p []string) bool {
+	for _, pattern := range s.multi {
+		if matchPattern(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) set(session *node, p []string, value string) *apiError {
+	if len(p) == 0 {
+		return badRequest("Invalid path: path must not be empty")
+	}
+
+	for _, validate := range s.validators {
+		if err := validate(p, value); err != nil {
+			return badRequest("\n\n  %s\n  Value validation failed\n  Set failed\n\n", err.Error())
+		}
+	}
+
+	parent := session.lookup(p[:len(p)-1])
+	if parent != nil && parent.isLeaf() {
+		return badRequest("Configuration path: [%s] is not valid\nSet failed\n", joinPath(p))
+	}
+
+	n := session.create(p)
+	if value == "" {
+		return nil
+	}
+	if len(n.children) > 0 {
+		return badRequest("Configuration path: [%s %s] is not valid\nSet failed\n", joinPath(p), value)
+	}
+
+	if !s.isMultiValue(p) {
+		n.values = []string{value}
+		return nil
+	}
+	for _, v := range n.values {
+		if v == value {
+			return nil
+		}
+	}
+	n.values = append(n.values, value)
+	return nil
+}
+
+func (s *Server) delete(session *node, p []string, value string) *apiError {
+	n := session.lookup(p)
+	if n == nil {
+		return badRequest("\n\n  Nothing to delete (the specified node does not exist)\n  Delete failed\n\n")
+	}
+
+	if value == "" {
+		session.remove(p)
+		return nil
+	}
+
+	values := []string{}
+	found := false
+	for _, v := range n.values {
+		if v == value {
+			found = true
+		} else {
+			values = append(values, v)
+		}
+	}
+	if !found {
+		return badRequest("\n\n  Nothing to delete (the specified value does not exist)\n  Delete failed\n\n")
+	}
+
+	n.values = values
+	if len(values) == 0 {
+		session.remove(p)
+	}
+	return nil
+}
+
+func (s *Server) comment(session *node, p []string, comment string) *apiError {
+	n := session.lookup(p)
+	if n == nil {
+		return badRequest("Configuration path: [%s] is not valid\nComment failed\n", joinPath(p))
+	}
+	n.comment = comment
+	return nil
+}
+
+func (s *Server) configFile(data any) (any, *apiError) {
+	obj, err := fields(data)
+	if err != nil {
+		return nil, err
+	}
+	op, err := stringField(obj, "op")
+	if err != nil {
+		return nil, err
+	}
+	file, err := stringField(obj, "file")
+	if err != nil {
+		return nil, err
+	}
+	if file == "" {
+		file = DefaultConfigFile
+	}
+
+	switch op {
+	case "save":
+		s.files[file] = s.running.clone()
+		return fmt.Sprintf("Saving configuration to '%s'...\nDone\n", file), nil
+
+	case "load":
+		saved, ok := s.files[file]
+		if !ok {
+			return nil, badRequest("Cannot open configuration file %s: No such file or directory", file)
+		}
+		s.running = saved.clone()
+		return nil, nil
+	}
+
+	return nil, badRequest("\"%s\" is not a valid operation", op)
+}
+
+func (s *Server) containerImage(data any) (any, *apiError) {
+	obj, err := fields(data)
+	if err != nil {
+		return nil, err
+	}
+	op, err := stringField(obj, "op")
+	if err != nil {
+		return nil, err
+	}
+	name, err := stringField(obj, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "add":
+		image := parseImageName(name)
+		for _, existing := range s.containerImages {
+			if existing.Repository == image.Repository && existing.Tag == image.Tag {
+				return nil, nil
+			}
+		}
+		image.ImageID = fmt.Sprintf("%012x", len(s.containerImages)+1)
+		s.containerImages = append(s.containerImages, image)
+		return nil, nil
+
+	case "delete":
+		image := parseImageName(name)
+		for i, existing := range s.containerImages {
+			if existing.Repository == image.Repository && existing.Tag == image.Tag || existing.ImageID == name {
+				s.containerImages = append(s.containerImages[:i], s.containerImages[i+1:]...)
+				return nil, nil
+			}
+		}
+		return nil, badRequest("Error: %s: image not known", name)
+
+	case "show":
+		lines := []string{"REPOSITORY                TAG         IMAGE ID      CREATED       SIZE"}
+		for _, image := range s.containerImages {
+			lines = append(lines, fmt.Sprintf("%-24s  %-10s  %-12s  2 weeks ago   7.33 MB", image.Repository, image.Tag, image.ImageID))
+		}
+		return strings.Join(lines, "\n") + "\n", nil
+	}
+
+	return nil, badRequest("\"%s\" is not a valid operation", op)
+}
+
+// Expand a short image name like `alpine:3.17` the way podman does
+func parseImageName(name string) ContainerImage {
+	repository, tag := name, "latest"
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		repository, tag = name[:i], name[i+1:]
+	}
+
+	if !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	if first := strings.Split(repository, "/")[0]; !strings.ContainsAny(first, ".:") && first != "localhost" {
+		repository = "docker.io/" + repository
+	}
+
+	return ContainerImage{repository, tag, ""}
+}
+
+func (s *Server) opMode(endpoint string) func(data any) (any, *apiError) {
+	return func(data any) (any, *apiError) {
+		obj, err := fields(data)
+		if err != nil {
+			return nil, err
+		}
+		p, err := pathField(obj, "path")
+		if err != nil {
+			return nil, err
+		}
+
+		output, ok := s.opmode[opKey(endpoint, p)]
+		if !ok && opKey(endpoint, p) == opKey("show", []string{"system", "image"}) {
+			output, ok = s.showSystemImage(), true
+		}
+		if !ok {
+			return nil, badRequest("\n\n  Invalid command: %s [%s]\n\n", endpoint, joinPath(p))
+		}
+		return output, nil
+	}
+}
+
+// Key an operational command by its endpoint and path elements, joined with a
+// separator which can't appear in an element
+func opKey(endpoint string, path []string) string {
+	return strings.Join(append([]string{endpoint}, path...), "\x00")
+}
+
+// Render the system images as `show system image` does, unless overridden
+// with HandleOp
+func (s *Server) showSystemImage() string {
+	lines := []string{
+		fmt.Sprintf("%-32s  %-14s  %-9s", "Name", "Default boot", "Running"),
+		fmt.Sprintf("%-32s  %-14s  %-9s", strings.Repeat("-", 32), strings.Repeat("-", 14), strings.Repeat("-", 9)),
+	}
+	for _, name := range s.systemImages {
+		isDefault := ""
+		if name == s.defaultImage {
+			isDefault = "Yes"
+		}
+		lines = append(lines, strings.TrimRight(fmt.Sprintf("%-32s  %-14s", name, isDefault), " "))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (s *Server) image(data any) (any, *apiError) {
+	obj, err := fields(data)
+	if err != nil {
+		return nil, err
+	}
+	op, err := stringField(obj, "op")
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "add":
+		url, err := stringField(obj, "url")
+		if err != nil {
+			return nil, err
+		}
+		if url == "" {
+			return nil, badRequest("Missing required field \"url\"")
+		}
+		name := strings.TrimSuffix(path.Base(url), ".iso")
+		name = strings.TrimPrefix(name, "vyos-")
+		name = strings.TrimSuffix(name, "-amd64")
+		s.systemImages = append(s.systemImages, name)
+		if s.defaultImage == "" {
+			s.defaultImage = name
+		}
+		return fmt.Sprintf("Image %s installed\n", name), nil
+
+	case "delete":
+		name, err := stringField(obj, "name")
+		if err != nil {
+			return nil, err
+		}
+		for i, existing := range s.systemImages {
+			if existing == name {
+				s.systemImages = append(s.systemImages[:i], s.systemImages[i+1:]...)
+				if s.defaultImage == name {
+					s.defaultImage = ""
+				}
+				return fmt.Sprintf("Image %s removed\n", name), nil
+			}
+		}
+		return nil, badRequest("The image \"%s\" cannot be found", name)
+
+	case "set_default":
+		name, err := stringField(obj, "name")
+		if err != nil {
+			return nil, err
+		}
+		for _, existing := range s.systemImages {
+			if existing == name {
+				s.defaultImage = name
+				return fmt.Sprintf("Default boot image has been set to \"%s\"\n", name), nil
+			}
+		}
+		return nil, badRequest("The image \"%s\" cannot be found", name)
+	}
+
+	return nil, badRequest("\"%s\" is not a valid operation", op)
+}
@@ -0,0 +1,290 @@
+package vyostest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Whether a Recorder captures or serves interactions
+type Mode int
+
+const (
+	// Forward requests to the real transport and capture them
+	ModeRecord Mode = iota
+	// Serve captured responses without touching the network
+	ModeReplay
+)
+
+// A single captured request and its response
+type Interaction struct {
+	Endpoint string `json:"endpoint"`
+	// The `data` form field, or the whole body of GraphQL requests, normalized
+	// so that key order does not matter
+	Data string `json:"data"`
+	// HTTP status and raw body of the response
+	Status int    `json:"status"`
+	Body   string `json:"body"`
+}
+
+// The file format of a recording
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// An http.RoundTripper which records VyOS API interactions to a cassette
+// file, or replays them from one.
+//
+// Pass it to client.NewWithClient with &http.Client{Transport: recorder}.
+// The API key is never written to the cassette: the `key` form field is
+// dropped, and in json request data and response bodies the key is replaced
+// with [REDACTED] where it is the value of a `key` field, such as in a
+// recorded configuration, or is set at a config path ending in `key`.
+// GraphQL requests, which are posted as json, are matched on their query and
+// variables, with the key in `variables.data.key` redacted the same way.
+type Recorder struct {
+	mode      Mode
+	file      string
+	transport http.RoundTripper
+
+	mutex    sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// Create a recorder for the cassette `file`.
+//
+// In ModeRecord requests are forwarded to `transport`, or
+// http.DefaultTransport if nil, and Save must be called to write the
+// cassette. In ModeReplay the cassette is loaded from `file` immediately.
+func NewRecorder(mode Mode, file string, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	r := &Recorder{mode: mode, file: file, transport: transport}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", file, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Return the interactions recorded or loaded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]Interaction{}, r.cassette.Interactions...)
+}
+
+// Write the recorded interactions to the cassette file. Does nothing in
+// ModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mutex.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.file, append(data, '\n'), 0644)
+}
+
+// Record or replay a single request, implementing http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := strings.TrimPrefix(req.URL.Path, "/")
+
+	body := []byte{}
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, key, err := formData(req.Header.Get("Content-Type"), body)
+	if err != nil {
+		return nil, fmt.Errorf("vyostest: failed to read request form: %w", err)
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, endpoint, data)
+	}
+
+	// Forward a copy of the request with the body restored
+	forward := req.Clone(req.Context())
+	forward.Body = io.NopCloser(bytes.NewReader(body))
+	forward.ContentLength = int64(len(body))
+
+	resp, err := r.transport.RoundTrip(forward)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mutex.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		endpoint,
+		data,
+		resp.StatusCode,
+		redactBody(respBody, key),
+	})
+	r.mutex.Unlock()
+
+	return resp, nil
+}
+
+// Serve the first unused interaction matching the request
+func (r *Recorder) replay(req *http.Request, endpoint string, data string) (*http.Response, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || interaction.Endpoint != endpoint || interaction.Data != data {
+			continue
+		}
+		r.used[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+			StatusCode:    interaction.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"application/json"}},
+			Body:          io.NopCloser(strings.NewReader(interaction.Body)),
+			ContentLength: int64(len(interaction.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("vyostest: no recorded interaction for %s with data %s", endpoint, data)
+}
+
+// Written to cassettes in place of the API key
+const redactedKey = "[REDACTED]"
+
+// Replace the API key in a decoded json value, wherever it is the value of a
+// `key` field or is set at a config path ending in `key`. Other strings equal
+// to the key, such as a host-name which happens to match it, are left alone.
+func redactKey(v any, key string) any {
+	return redactValue(v, key, false)
+}
+
+func redactValue(v any, key string, secret bool) any {
+	switch v := v.(type) {
+	case string:
+		if secret && key != "" && v == key {
+			return redactedKey
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i], key, i > 0 && v[i-1] == "key")
+		}
+	case map[string]any:
+		path, _ := v["path"].([]any)
+		setsKey := len(path) > 0 && path[len(path)-1] == "key"
+		for k := range v {
+			v[k] = redactValue(v[k], key, k == "key" || k == "value" && setsKey)
+		}
+	}
+	return v
+}
+
+// Redact the API key from a json response body, which is returned unchanged
+// if it is not json
+func redactBody(body []byte, key string) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var v any
+	if key == "" || decoder.Decode(&v) != nil {
+		return string(body)
+	}
+	redacted, err := json.Marshal(redactKey(v, key))
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+// Extract the API key and the normalized request data, with the key redacted,
+// from a form encoded request body or from a json GraphQL request body
+func formData(contentType string, body []byte) (string, string, error) {
+	var raw, key string
+
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" {
+		var req map[string]any
+		if err := json.Unmarshal(body, &req); err != nil {
+			return "", "", err
+		}
+		if variables, ok := req["variables"].(map[string]any); ok {
+			if input, ok := variables["data"].(map[string]any); ok {
+				key, _ = input["key"].(string)
+			}
+		}
+
+		normalized, err := json.Marshal(redactKey(req, key))
+		if err != nil {
+			return "", "", err
+		}
+		return string(normalized), key, nil
+	}
+	if mediaType == "multipart/form-data" {
+		form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(1 << 20)
+		if err != nil {
+			return "", "", err
+		}
+		if values := form.Value["data"]; len(values) > 0 {
+			raw = values[0]
+		}
+		if values := form.Value["key"]; len(values) > 0 {
+			key = values[0]
+		}
+	} else {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "", "", err
+		}
+		raw = values.Get("data")
+		key = values.Get("key")
+	}
+
+	// Re-encode so that equivalent payloads compare equal
+	var data any
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return raw, key, nil
+	}
+	normalized, err := json.Marshal(redactKey(data, key))
+	if err != nil {
+		return "", "", err
+	}
+	return string(normalized), key, nil
+}
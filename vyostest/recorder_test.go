@@ -0,0 +1,185 @@
+package vyostest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/foltik/vyos-client-go/client"
+	"github.com/foltik/vyos-client-go/vyostest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Recorder_RecordReplay(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	// Record against a live (fake) server
+	server := vyostest.NewServer()
+	server.Key = "secret-key"
+	recorder, err := vyostest.NewRecorder(vyostest.ModeRecord, cassette, server.Client().Transport)
+	assert.NoError(t, err)
+
+	c := client.NewWithClient(&http.Client{Transport: recorder}, server.URL, "secret-key")
+	assert.NoError(t, c.Config.Set(ctx, "system host-name", "router"))
+	value, err := c.Config.ReturnValue(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "router", value)
+	assert.NoError(t, c.ContainerImages.Add(ctx, "alpine:3.17.3"))
+	images, err := c.ContainerImages.Show(ctx)
+	assert.NoError(t, err)
+
+	assert.NoError(t, recorder.Save())
+	server.Close()
+
+	// The key is never written
+	data, err := os.ReadFile(cassette)
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(data), "secret-key"), "cassette must not contain the API key")
+	assert.Len(t, recorder.Interactions(), 4)
+
+	// Replay without a server
+	replayer, err := vyostest.NewRecorder(vyostest.ModeReplay, cassette, nil)
+	assert.NoError(t, err)
+
+	c = client.NewWithClient(&http.Client{Transport: replayer}, server.URL, "other-key")
+	assert.NoError(t, c.Config.Set(ctx, "system host-name", "router"))
+	value, err = c.Config.ReturnValue(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "router", value)
+	assert.NoError(t, c.ContainerImages.Add(ctx, "alpine:3.17.3"))
+	replayed, err := c.ContainerImages.Show(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, images, replayed)
+
+	// Each interaction is only served once
+	_, err = c.Config.ReturnValue(ctx, "system host-name")
+	assert.ErrorContains(t, err, "no recorded interaction for retrieve")
+
+	// Unrecorded requests fail
+	_, err = c.Config.Show(ctx, "interfaces")
+	assert.ErrorContains(t, err, "no recorded interaction for retrieve")
+}
+
+func TestUnit_Recorder_RedactsKey(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	server := vyostest.NewServer()
+	defer server.Close()
+	server.Key = "secret-key"
+	recorder, err := vyostest.NewRecorder(vyostest.ModeRecord, cassette, server.Client().Transport)
+	assert.NoError(t, err)
+
+	// The key appears in the request data and in the returned configuration
+	c := client.NewWithClient(&http.Client{Transport: recorder}, server.URL, "secret-key")
+	assert.NoError(t, c.Config.Set(ctx, "service https api keys id apikey key", "secret-key"))
+	config, err := c.Config.Show(ctx, "service https api")
+	assert.NoError(t, err)
+	assert.Contains(t, fmt.Sprint(config), "secret-key")
+
+	assert.NoError(t, recorder.Save())
+	data, err := os.ReadFile(cassette)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-key")
+	assert.Contains(t, string(data), "[REDACTED]")
+}
+
+// Values which happen to equal the key are not redacted
+func TestUnit_Recorder_KeyAsValue(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	server := vyostest.NewServer()
+	recorder, err := vyostest.NewRecorder(vyostest.ModeRecord, cassette, server.Client().Transport)
+	assert.NoError(t, err)
+
+	c := client.NewWithClient(&http.Client{Transport: recorder}, server.URL, vyostest.DefaultKey)
+	assert.NoError(t, c.Config.Set(ctx, "system host-name", "vyos"))
+	value, err := c.Config.ReturnValue(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "vyos", value)
+	_, err = c.Config.Show(ctx, "service https api")
+	assert.NoError(t, err)
+
+	assert.NoError(t, recorder.Save())
+	server.Close()
+
+	replayer, err := vyostest.NewRecorder(vyostest.ModeReplay, cassette, nil)
+	assert.NoError(t, err)
+	c = client.NewWithClient(&http.Client{Transport: replayer}, server.URL, vyostest.DefaultKey)
+	assert.NoError(t, c.Config.Set(ctx, "system host-name", "vyos"))
+	value, err = c.Config.ReturnValue(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "vyos", value)
+
+	// The key itself is still redacted from the configuration
+	config, err := c.Config.Show(ctx, "service https api")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"keys": map[string]any{"id": map[string]any{"apikey": map[string]any{"key": "[REDACTED]"}}},
+	}, config)
+}
+
+func TestUnit_Recorder_GraphQL(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	// Answer ShowConfig with the last element of the requested path
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Data struct {
+					Key  string   `json:"key"`
+					Path []string `json:"path"`
+				} `json:"data"`
+			} `json:"variables"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "secret-key", req.Variables.Data.Key)
+
+		path := req.Variables.Data.Path
+		result, _ := json.Marshal(path[len(path)-1])
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data": {"ShowConfig": {"success": true, "errors": [], "data": {"result": %s}}}}`, result)
+	}))
+	defer server.Close()
+
+	recorder, err := vyostest.NewRecorder(vyostest.ModeRecord, cassette, server.Client().Transport)
+	assert.NoError(t, err)
+	c := client.NewWithClient(&http.Client{Transport: recorder}, server.URL, "secret-key")
+	for _, path := range []string{"system host-name", "system domain-name"} {
+		_, err := c.GraphQL.ShowConfig(ctx, client.Path(strings.Fields(path)))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, recorder.Save())
+
+	data, err := os.ReadFile(cassette)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-key")
+	assert.Contains(t, string(data), "domain-name")
+
+	// Requests are matched on their variables, not just their order
+	replayer, err := vyostest.NewRecorder(vyostest.ModeReplay, cassette, nil)
+	assert.NoError(t, err)
+	c = client.NewWithClient(&http.Client{Transport: replayer}, server.URL, "secret-key")
+	for _, path := range []string{"system domain-name", "system host-name"} {
+		result, err := c.GraphQL.ShowConfig(ctx, client.Path(strings.Fields(path)))
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Fields(path)[1], result)
+	}
+
+	_, err = c.GraphQL.ShowConfig(ctx, client.Path{"system", "time-zone"})
+	assert.ErrorContains(t, err, "no recorded interaction for graphql")
+}
+
+func TestUnit_Recorder_MissingCassette(t *testing.T) {
+	_, err := vyostest.NewRecorder(vyostest.ModeReplay, filepath.Join(t.TempDir(), "missing.json"), nil)
+	assert.ErrorContains(t, err, "failed to read cassette")
+}
@@ -0,0 +1,332 @@
+// Package vyostest provides an in-process fake of the VyOS HTTP API, for
+// testing code built on the client package without a router.
+//
+// The fake keeps the configuration in memory and follows VyOS semantics
+// closely enough for most tests: tag nodes, multi-value leaves, valueless
+// nodes, "specified path is empty" errors and API key checks. Operational
+// endpoints such as `show` and `generate` answer with canned output
+// registered through HandleOp.
+package vyostest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/foltik/vyos-client-go/client"
+)
+
+// The API key accepted by servers created with NewServer
+const DefaultKey = "vyos"
+
+// Path patterns of multi-value leaves known to the server by default. An
+// element of `*` matches any single element, such as an interface name.
+var DefaultMultiValue = []string{
+	"system name-server",
+	"system domain-search",
+	"system domain-search domain",
+	"interfaces * * address",
+	"interfaces * * vif * address",
+	"service dns forwarding allow-from",
+	"service dns forwarding listen-address",
+	"service dns forwarding name-server",
+}
+
+// A request received by the server
+type Request struct {
+	Endpoint string
+	// The decoded `data` form field
+	Data any
+}
+
+// A container image known to the server
+type ContainerImage struct {
+	Repository string
+	Tag        string
+	ImageID    string
+}
+
+// A function validating a single `set` operation before it is applied.
+// Returning an error rejects the whole batch with the error's message.
+type Validator func(path []string, value string) error
+
+// A fake VyOS HTTP API server
+type Server struct {
+	*httptest.Server
+
+	// API key required in every request
+	Key string
+
+	mutex           sync.Mutex
+	running         *node
+	pending         *node
+	files           map[string]*node
+	multi           [][]string
+	validators      []Validator
+	opmode          map[string]string
+	containerImages []ContainerImage
+	systemImages    []string
+	defaultImage    string
+	requests        []Request
+}
+
+// The file saved to and loaded from when none is specified
+const DefaultConfigFile = "/config/config.boot"
+
+// Start a fake server accepting DefaultKey, with a minimal default
+// configuration. The caller should call Close when finished.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+	return s
+}
+
+// Create a fake server which is not yet started, so that it can be
+// configured first. Call Start or StartTLS to start it.
+func NewUnstartedServer() *Server {
+	s := &Server{
+		Key:     DefaultKey,
+		running: newNode(),
+		files:   map[string]*node{},
+		opmode:  map[string]string{},
+	}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+
+	s.MultiValue(DefaultMultiValue...)
+	s.LoadConfig(map[string]any{
+		"system": map[string]any{
+			"host-name":   "vyos",
+			"name-server": []any{"1.1.1.1", "1.0.0.1"},
+		},
+		"interfaces": map[string]any{
+			"loopback": map[string]any{"lo": map[string]any{}},
+		},
+		"service": map[string]any{
+			"https": map[string]any{
+				"api": map[string]any{
+					"keys": map[string]any{
+						"id": map[string]any{"apikey": map[string]any{"key": DefaultKey}},
+					},
+				},
+			},
+		},
+	})
+
+	return s
+}
+
+// Register path patterns of multi-value leaves, where `set` adds a value
+// instead of replacing it. Elements of `*` match any single element.
+func (s *Server) MultiValue(patterns ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, pattern := range patterns {
+		s.multi = append(s.multi, strings.Fields(pattern))
+	}
+}
+
+// Register a validator run against every `set` operation
+func (s *Server) Validate(v Validator) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.validators = append(s.validators, v)
+}
+
+// Register the output of an operational command, e.g.
+// HandleOp("show", client.Path{"version"}, "Version: VyOS 1.4.0").
+//
+// `endpoint` is one of `show`, `generate` or `reset`, and `path` is the
+// command after it. Elements are matched exactly, so they may contain spaces.
+func (s *Server) HandleOp(endpoint string, path client.Path, output string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.opmode[opKey(endpoint, path)] = output
+}
+
+// Replace the running configuration with `tree`, which has the shape
+// returned by the `showConfig` operation.
+func (s *Server) LoadConfig(tree map[string]any) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.running = newNode()
+	s.running.load(tree)
+}
+
+// Return the whole running configuration, in the shape returned by the
+// `showConfig` operation.
+func (s *Server) Config() map[string]any {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.running.render().(map[string]any)
+}
+
+// Return the requests received so far
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Request{}, s.requests...)
+}
+
+// Check whether a commit-confirm is waiting to be confirmed
+func (s *Server) PendingConfirm() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.pending != nil
+}
+
+// Revert a pending commit-confirm, as if its timeout had expired
+func (s *Server) ExpireConfirm() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.pending != nil {
+		s.running = s.pending
+		s.pending = nil
+	}
+}
+
+// Return the container images known to the server
+func (s *Server) ContainerImages() []ContainerImage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]ContainerImage{}, s.containerImages...)
+}
+
+// Return the names of the system images known to the server
+func (s *Server) SystemImages() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string{}, s.systemImages...)
+}
+
+// Return the name of the system image booted by default, empty if none
+func (s *Server) DefaultSystemImage() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.defaultImage
+}
+
+// An error returned to the client in the VyOS response format
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func badRequest(format string, args ...any) *apiError {
+	return &apiError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint := strings.TrimPrefix(r.URL.Path, "/")
+	handler, ok := map[string]func(data any) (any, *apiError){
+		"retrieve":        s.retrieve,
+		"configure":       s.configure,
+		"config-file":     s.configFile,
+		"container-image": s.containerImage,
+		"show":            s.opMode("show"),
+		"generate":        s.opMode("generate"),
+		"reset":           s.opMode("reset"),
+		"image":           s.image,
+	}[endpoint]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{"detail": "Not Found"})
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{"detail": "Method Not Allowed"})
+		return
+	}
+
+	if r.FormValue("key") != s.Key {
+		writeError(w, &apiError{http.StatusUnauthorized, "Valid API key is required"})
+		return
+	}
+
+	var data any
+	if err := json.Unmarshal([]byte(r.FormValue("data")), &data); err != nil {
+		writeError(w, badRequest("Failed to parse JSON: %s", err.Error()))
+		return
+	}
+
+	s.mutex.Lock()
+	s.requests = append(s.requests, Request{endpoint, data})
+	result, apiErr := handler(data)
+	s.mutex.Unlock()
+
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"success": true, "data": result, "error": nil})
+}
+
+func writeError(w http.ResponseWriter, err *apiError) {
+	writeJSON(w, err.status, map[string]any{"success": false, "data": nil, "error": err.message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// Decode a JSON object request into its fields
+func fields(data any) (map[string]any, *apiError) {
+	obj, ok := data.(map[string]any)
+	if !ok {
+		return nil, badRequest("Malformed request: expected an object")
+	}
+	return obj, nil
+}
+
+// Decode the string field `key`, which may be absent
+func stringField(obj map[string]any, key string) (string, *apiError) {
+	v, ok := obj[key]
+	if !ok || v == nil {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", badRequest("Malformed request: %s must be a string", key)
+	}
+	return s, nil
+}
+
+// Decode the list of strings field `key`, which may be absent
+func pathField(obj map[string]any, key string) ([]string, *apiError) {
+	v, ok := obj[key]
+	if !ok || v == nil {
+		return []string{}, nil
+	}
+	array, ok := v.([]any)
+	if !ok {
+		return nil, badRequest("Malformed request: %s must be a list", key)
+	}
+
+	path := []string{}
+	for _, elem := range array {
+		s, ok := elem.(string)
+		if !ok {
+			return nil, badRequest("Malformed request: %s must be a list of strings", key)
+		}
+		path = append(path, s)
+	}
+	return path, nil
+}
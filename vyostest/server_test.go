@@ -0,0 +1,311 @@
+package vyostest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/foltik/vyos-client-go/client"
+	"github.com/foltik/vyos-client-go/vyostest"
+	"github.com/stretchr/testify/assert"
+)
+
+func make_client(t *testing.T) (*vyostest.Server, *client.Client, context.Context) {
+	server := vyostest.NewServer()
+	t.Cleanup(server.Close)
+
+	c := client.NewWithClient(server.Client(), server.URL, vyostest.DefaultKey)
+	return server, c, context.Background()
+}
+
+func TestUnit_Server_Unauthorized(t *testing.T) {
+	server, _, ctx := make_client(t)
+
+	c := client.NewWithClient(server.Client(), server.URL, "wrong")
+	_, err := c.Config.Show(ctx, "system")
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+}
+
+func TestUnit_Server_NotFound(t *testing.T) {
+	server, c, ctx := make_client(t)
+
+	_, err := c.Request(ctx, "foo", map[string]any{"op": "foo"})
+	assert.ErrorContains(t, err, fmt.Sprintf("received non-successful (404) response from vyos api (%s/foo)", server.URL))
+}
+
+func TestUnit_Server_Show(t *testing.T) {
+	_, c, ctx := make_client(t)
+
+	// Leaf
+	resp, err := c.Config.Show(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "vyos", resp)
+
+	// Multi-value leaf
+	resp, err = c.Config.Show(ctx, "system name-server")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"1.1.1.1", "1.0.0.1"}, resp)
+
+	// Tag node
+	resp, err = c.Config.Show(ctx, "service https api keys id apikey")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"key": "vyos"}, resp)
+
+	// Root
+	resp, err = c.Config.Show(ctx, "")
+	assert.NoError(t, err)
+	assert.Contains(t, resp, "system")
+
+	// Missing
+	resp, err = c.Config.Show(ctx, "system domain-name")
+	assert.NoError(t, err)
+	assert.Nil(t, resp)
+	_, err = c.Request(ctx, "retrieve", map[string]any{"op": "showConfig", "path": []string{"foo"}})
+	assert.ErrorIs(t, err, client.ErrPathEmpty)
+}
+
+func TestUnit_Server_Retrieve(t *testing.T) {
+	_, c, ctx := make_client(t)
+
+	exists, err := c.Config.Exists(ctx, "interfaces loopback lo")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = c.Config.Exists(ctx, "interfaces loopback lo0")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	value, err := c.Config.ReturnValue(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "vyos", value)
+
+	_, err = c.Config.ReturnValue(ctx, "system domain-name")
+	assert.ErrorIs(t, err, client.ErrPathEmpty)
+
+	values, err := c.Config.ReturnValues(ctx, "system name-server")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.1.1.1", "1.0.0.1"}, values)
+}
+
+func TestUnit_Server_SetDelete(t *testing.T) {
+	server, c, ctx := make_client(t)
+
+	// Leaves are replaced
+	err := c.Config.Set(ctx, "system host-name", "router")
+	assert.NoError(t, err)
+	assert.Equal(t, "router", server.Config()["system"].(map[string]any)["host-name"])
+
+	// Multi-value leaves are added to
+	err = c.Config.Set(ctx, "system name-server", "9.9.9.9")
+	assert.NoError(t, err)
+	values, _ := c.Config.ReturnValues(ctx, "system name-server")
+	assert.Equal(t, []string{"1.1.1.1", "1.0.0.1", "9.9.9.9"}, values)
+
+	// Valueless nodes and tag nodes
+	err = c.Config.Set(ctx, "", map[string]any{
+		"system": map[string]any{"option": map[string]any{"startup-beep": ""}},
+		"interfaces": map[string]any{
+			"ethernet": map[string]any{
+				"eth0": map[string]any{
+					"address":     []string{"10.0.0.1/24", "10.0.1.1/24"},
+					"description": "uplink to core",
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	resp, err := c.Config.Show(ctx, "interfaces ethernet")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"eth0": map[string]any{
+			"address":     []any{"10.0.0.1/24", "10.0.1.1/24"},
+			"description": "uplink to core",
+		},
+	}, resp)
+	resp, err = c.Config.Show(ctx, "system option")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"startup-beep": map[string]any{}}, resp)
+
+	// Delete a single value
+	err = c.Config.Delete(ctx, "system name-server", "1.1.1.1")
+	assert.NoError(t, err)
+	values, _ = c.Config.ReturnValues(ctx, "system name-server")
+	assert.Equal(t, []string{"1.0.0.1", "9.9.9.9"}, values)
+
+	// Deleting the last child removes empty parents
+	err = c.Config.Delete(ctx, "system option startup-beep")
+	assert.NoError(t, err)
+	exists, _ := c.Config.Exists(ctx, "system option")
+	assert.False(t, exists)
+
+	// Deleting a missing node fails
+	err = c.Config.Delete(ctx, "system option")
+	assert.ErrorContains(t, err, "Nothing to delete")
+}
+
+func TestUnit_Server_Batch(t *testing.T) {
+	server, c, ctx := make_client(t)
+	server.Validate(func(path []string, value string) error {
+		if path[len(path)-1] == "mtu" && value == "99999" {
+			return errors.New("Number 99999 is not in any of accepted ranges")
+		}
+		return nil
+	})
+
+	// A failing batch leaves the configuration untouched
+	err := c.Config.Begin().
+		Set("system host-name", "router").
+		Set("interfaces ethernet eth0 mtu", "99999").
+		Commit(ctx)
+	var configureErr *client.ConfigureError
+	assert.True(t, errors.As(err, &configureErr), "expected a *ConfigureError")
+	assert.Len(t, configureErr.Failed, 1)
+	assert.Equal(t, 1, configureErr.Failed[0].Index)
+
+	value, _ := c.Config.ReturnValue(ctx, "system host-name")
+	assert.Equal(t, "vyos", value)
+
+	// Apply converges on the desired state
+	plan, err := c.Config.Apply(ctx, "system", map[string]any{
+		"host-name":   "router",
+		"name-server": []string{"9.9.9.9"},
+	})
+	assert.NoError(t, err)
+	assert.False(t, plan.IsEmpty())
+
+	plan, err = c.Config.Plan(ctx, "system", map[string]any{
+		"host-name":   "router",
+		"name-server": []string{"9.9.9.9"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, plan.IsEmpty(), "expected empty plan, got:\n%s", plan)
+}
+
+func TestUnit_Server_ApplyModify(t *testing.T) {
+	server, c, ctx := make_client(t)
+
+	// A changed single value is replaced by a set alone
+	plan, err := c.Config.Apply(ctx, "system host-name", "router")
+	assert.NoError(t, err)
+	assert.Equal(t, "+set system host-name 'router'\n", plan.String())
+
+	requests := server.Requests()
+	assert.Equal(t, vyostest.Request{
+		Endpoint: "configure",
+		Data: []any{
+			map[string]any{"op": "set", "path": []any{"system", "host-name"}, "value": "router"},
+		},
+	}, requests[len(requests)-1])
+
+	value, err := c.Config.ReturnValue(ctx, "system host-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "router", value)
+}
+
+func TestUnit_Server_CommitConfirm(t *testing.T) {
+	server, c, ctx := make_client(t)
+
+	ops := c.Config.Begin().Set("system host-name", "router").Operations()
+	err := c.Config.CommitConfirm(ctx, 5, ops)
+	assert.NoError(t, err)
+	assert.True(t, server.PendingConfirm())
+
+	server.ExpireConfirm()
+	value, _ := c.Config.ReturnValue(ctx, "system host-name")
+	assert.Equal(t, "vyos", value)
+
+	err = c.Config.CommitConfirmWith(ctx, 5, ops, nil)
+	assert.NoError(t, err)
+	assert.False(t, server.PendingConfirm())
+	value, _ = c.Config.ReturnValue(ctx, "system host-name")
+	assert.Equal(t, "router", value)
+}
+
+func TestUnit_Server_ConfigFile(t *testing.T) {
+	_, c, ctx := make_client(t)
+
+	assert.NoError(t, c.Config.SaveFile(ctx, "/config/test.boot"))
+	assert.NoError(t, c.Config.Set(ctx, "system host-name", "router"))
+	assert.NoError(t, c.Config.LoadFile(ctx, "/config/test.boot"))
+
+	value, _ := c.Config.ReturnValue(ctx, "system host-name")
+	assert.Equal(t, "vyos", value)
+
+	assert.Error(t, c.Config.LoadFile(ctx, "/config/missing.boot"))
+}
+
+func TestUnit_Server_ContainerImages(t *testing.T) {
+	_, c, ctx := make_client(t)
+
+	err := c.ContainerImages.Add(ctx, "alpine:3.17.3")
+	assert.NoError(t, err)
+
+	images, err := c.ContainerImages.Show(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, images, 1)
+	assert.Equal(t, "docker.io/library/alpine", images[0].Name)
+	assert.Equal(t, "3.17.3", images[0].Tag)
+
+	err = c.ContainerImages.Delete(ctx, "alpine:3.17.3")
+	assert.NoError(t, err)
+
+	images, err = c.ContainerImages.Show(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestUnit_Server_OpMode(t *testing.T) {
+	server, c, ctx := make_client(t)
+	server.HandleOp("show", client.Path{"version"}, "Version:          VyOS 1.4.0\n")
+
+	resp, err := c.Request(ctx, "show", map[string]any{"op": "show", "path": []string{"version"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Version:          VyOS 1.4.0\n", resp)
+
+	_, err = c.Request(ctx, "show", map[string]any{"op": "show", "path": []string{"foo"}})
+	assert.ErrorContains(t, err, "Invalid command")
+
+	assert.Equal(t, "show", server.Requests()[0].Endpoint)
+
+	// Elements containing spaces don't collide with separate elements
+	server.HandleOp("show", client.Path{"interfaces", "description", "to core"}, "one element")
+	server.HandleOp("show", client.Path{"interfaces", "description", "to", "core"}, "two elements")
+	resp, err = c.Request(ctx, "show", map[string]any{"op": "show", "path": []string{"interfaces", "description", "to core"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "one element", resp)
+	resp, err = c.Request(ctx, "show", map[string]any{"op": "show", "path": []string{"interfaces", "description", "to", "core"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "two elements", resp)
+}
+
+func TestUnit_Server_Image(t *testing.T) {
+	server, c, ctx := make_client(t)
+
+	_, err := c.Request(ctx, "image", map[string]any{
+		"op":  "add",
+		"url": "https://example.com/vyos-1.4.0-amd64.iso",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.4.0"}, server.SystemImages())
+
+	assert.NoError(t, c.Images.Add(ctx, "https://example.com/vyos-1.4.1-amd64.iso"))
+	assert.Equal(t, "1.4.0", server.DefaultSystemImage())
+
+	assert.NoError(t, c.Images.SetDefault(ctx, "1.4.1"))
+	assert.Equal(t, "1.4.1", server.DefaultSystemImage())
+	assert.Error(t, c.Images.SetDefault(ctx, "1.5.0"))
+
+	images, err := c.Images.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []client.SystemImage{
+		{Name: "1.4.0"},
+		{Name: "1.4.1", Default: true},
+	}, images)
+
+	_, err = c.Request(ctx, "image", map[string]any{"op": "delete", "name": "1.4.0"})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Images.Delete(ctx, "1.4.1"))
+	assert.Empty(t, server.SystemImages())
+	assert.Empty(t, server.DefaultSystemImage())
+}
@@ -0,0 +1,145 @@
+package vyostest
+
+import (
+	"strings"
+)
+
+// A node in the in-memory configuration tree
+type node struct {
+	children map[string]*node
+	values   []string
+	comment  string
+}
+
+func newNode() *node {
+	return &node{children: map[string]*node{}}
+}
+
+func (n *node) clone() *node {
+	res := newNode()
+	res.values = append([]string{}, n.values...)
+	res.comment = n.comment
+	for k, child := range n.children {
+		res.children[k] = child.clone()
+	}
+	return res
+}
+
+func (n *node) isLeaf() bool {
+	return len(n.values) > 0
+}
+
+func (n *node) isEmpty() bool {
+	return len(n.values) == 0 && len(n.children) == 0
+}
+
+// Return the node at `path`, or nil if it does not exist
+func (n *node) lookup(path []string) *node {
+	cur := n
+	for _, elem := range path {
+		child, ok := cur.children[elem]
+		if !ok {
+			return nil
+		}
+		cur = child
+	}
+	return cur
+}
+
+// Return the node at `path`, creating it and any missing parents
+func (n *node) create(path []string) *node {
+	cur := n
+	for _, elem := range path {
+		child, ok := cur.children[elem]
+		if !ok {
+			child = newNode()
+			cur.children[elem] = child
+		}
+		cur = child
+	}
+	return cur
+}
+
+// Remove the node at `path` along with any parents left empty
+func (n *node) remove(path []string) {
+	if len(path) == 0 {
+		n.children = map[string]*node{}
+		n.values = nil
+		return
+	}
+
+	child, ok := n.children[path[0]]
+	if !ok {
+		return
+	}
+
+	if len(path) == 1 {
+		delete(n.children, path[0])
+		return
+	}
+
+	child.remove(path[1:])
+	if child.isEmpty() {
+		delete(n.children, path[0])
+	}
+}
+
+// Render the node the way the VyOS API does: a single value as a string,
+// several values as a list, and everything else as an object.
+func (n *node) render() any {
+	switch len(n.values) {
+	case 0:
+		obj := map[string]any{}
+		for k, child := range n.children {
+			obj[k] = child.render()
+		}
+		return obj
+	case 1:
+		return n.values[0]
+	default:
+		values := []any{}
+		for _, v := range n.values {
+			values = append(values, v)
+		}
+		return values
+	}
+}
+
+// Load a tree of the shape returned by render into the node
+func (n *node) load(tree any) {
+	switch tree := tree.(type) {
+	case map[string]any:
+		for k, v := range tree {
+			n.create([]string{k}).load(v)
+		}
+	case map[string]string:
+		for k, v := range tree {
+			n.create([]string{k}).load(v)
+		}
+	case []string:
+		n.values = append(n.values, tree...)
+	case []any:
+		for _, v := range tree {
+			n.load(v)
+		}
+	case string:
+		n.values = append(n.values, tree)
+	}
+}
+
+// Match `path` against a pattern where `*` matches any single element
+func matchPattern(pattern []string, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i := range pattern {
+		if pattern[i] != "*" && pattern[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func joinPath(path []string) string {
+	return strings.Join(path, " ")
+}